@@ -1,10 +1,12 @@
 module github.com/earthboundkid/scooter
 
-go 1.23
+go 1.23.0
 
 require (
+	github.com/BurntSushi/toml v1.4.0
 	github.com/carlmjohnson/exitcode v0.20.2
 	github.com/carlmjohnson/flagx v0.22.2
 	github.com/carlmjohnson/versioninfo v0.22.5
 	github.com/progrium/darwinkit v0.5.0
+	golang.org/x/text v0.28.0
 )
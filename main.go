@@ -1,12 +1,18 @@
 package main
 
 import (
+	"context"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/carlmjohnson/exitcode"
 	"github.com/earthboundkid/scooter/mvfiles"
 )
 
 func main() {
-	exitcode.Exit(mvfiles.CLI(os.Args[1:]))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := mvfiles.CLI(ctx, os.Args[1:])
+	stop()
+	exitcode.Exit(err)
 }
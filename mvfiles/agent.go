@@ -0,0 +1,142 @@
+package mvfiles
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const agentLabel = "com.github.earthboundkid.scooter"
+
+var agentPlist = template.Must(template.New("plist").
+	Funcs(template.FuncMap{"xml": xmlEscape}).
+	Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>{{xml .Label}}</string>
+	<key>ProgramArguments</key>
+	<array>
+{{- range .Args}}
+		<string>{{xml .}}</string>
+{{- end}}
+	</array>
+	<key>StartInterval</key>
+	<integer>{{.Interval}}</integer>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>StandardOutPath</key>
+	<string>{{xml .Log}}</string>
+	<key>StandardErrorPath</key>
+	<string>{{xml .Log}}</string>
+</dict>
+</plist>
+`))
+
+func xmlEscape(s string) string {
+	var sb strings.Builder
+	_ = xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
+
+func agentPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", agentLabel+".plist"), nil
+}
+
+func launchDomain() string {
+	return fmt.Sprintf("gui/%d", os.Getuid())
+}
+
+func launchctl(ctx context.Context, args ...string) error {
+	out, err := exec.CommandContext(ctx, "launchctl", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("launchctl %s: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// InstallAgent writes and loads a launchd agent that runs
+// scooter move on app.dir every app.every, passing along app.args.
+// Files modified less than app.opts.Settle ago are left for the next run.
+func (app *appEnv) InstallAgent(ctx context.Context) error {
+	if app.every < time.Second {
+		return errors.New("-every must be at least one second")
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return err
+	}
+	dir, err := filepath.Abs(app.dir)
+	if err != nil {
+		return err
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	name, err := agentPath()
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	var sb strings.Builder
+	if err = agentPlist.Execute(&sb, struct {
+		Label    string
+		Args     []string
+		Interval int64
+		Log      string
+	}{
+		Label:    agentLabel,
+		Args:     append([]string{exe, "move", "-dir", dir, "-settle", app.opts.Settle.String()}, app.args...),
+		Interval: int64(app.every.Seconds()),
+		Log:      filepath.Join(home, "Library", "Logs", AppName+".log"),
+	}); err != nil {
+		return err
+	}
+	// Replace any agent that is already loaded
+	_ = launchctl(ctx, "bootout", launchDomain()+"/"+agentLabel)
+	if err = os.WriteFile(name, []byte(sb.String()), 0o644); err != nil {
+		return err
+	}
+	if err = launchctl(ctx, "bootstrap", launchDomain(), name); err != nil {
+		return err
+	}
+	app.Info("installed agent", "path", name)
+	return nil
+}
+
+// UninstallAgent unloads and removes the agent written by InstallAgent.
+func (app *appEnv) UninstallAgent(ctx context.Context) error {
+	name, err := agentPath()
+	if err != nil {
+		return err
+	}
+	if _, err = os.Stat(name); err != nil {
+		return fmt.Errorf("no agent installed: %w", err)
+	}
+	if err = launchctl(ctx, "bootout", launchDomain()+"/"+agentLabel); err != nil {
+		app.Warn("unloading agent", "error", err)
+	}
+	if err = os.Remove(name); err != nil {
+		return err
+	}
+	app.Info("removed agent", "path", name)
+	return nil
+}
@@ -0,0 +1,116 @@
+package mvfiles
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// Apply executes the plan in the CSV file named by the first argument
+// after checking that it can be carried out safely.
+func (app *appEnv) Apply(ctx context.Context) error {
+	moves, err := readPlan(app.args[0])
+	if err != nil {
+		return err
+	}
+	if err = validatePlan(moves); err != nil {
+		return err
+	}
+	if app.dryRun {
+		return app.printPlan(moves)
+	}
+	return app.execute(ctx, moves)
+}
+
+// readPlan reads a CSV file in the format written by writePlan.
+func readPlan(name string) ([]Move, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading plan header: %w", err)
+	}
+	oldcol, newcol, dupcol, sumcol := -1, -1, -1, -1
+	for i, col := range header {
+		switch col {
+		case "old":
+			oldcol = i
+		case "new":
+			newcol = i
+		case "duplicate":
+			dupcol = i
+		case "checksum":
+			sumcol = i
+		}
+	}
+	if oldcol == -1 || newcol == -1 {
+		return nil, fmt.Errorf("plan %q must have old and new columns", name)
+	}
+	var moves []Move
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			return moves, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		m := Move{Old: row[oldcol], New: row[newcol]}
+		if dupcol != -1 {
+			m.Duplicate, _ = strconv.ParseBool(row[dupcol])
+		}
+		if sumcol != -1 {
+			m.Checksum = row[sumcol]
+		}
+		moves = append(moves, m)
+	}
+}
+
+// validatePlan checks that every source exists
+// and that every destination is unique and unoccupied,
+// except for duplicates, which are not moved.
+func validatePlan(moves []Move) error {
+	var errs []error
+	seenOld := make(map[string]bool, len(moves))
+	seenNew := make(pathSet, len(moves))
+	for _, m := range moves {
+		if m.Old == "" || m.New == "" {
+			errs = append(errs, fmt.Errorf("incomplete row: %q → %q", m.Old, m.New))
+			continue
+		}
+		if seenOld[m.Old] {
+			errs = append(errs, fmt.Errorf("source listed more than once: %q", m.Old))
+		}
+		seenOld[m.Old] = true
+		if m.Duplicate || isPhotosImport(m.New) {
+			if _, err := os.Lstat(m.Old); err != nil {
+				errs = append(errs, fmt.Errorf("source missing: %w", err))
+			}
+			continue
+		}
+		// Compare destinations the way the file system does,
+		// so that Report.pdf and report.pdf don't overwrite each other
+		if seenNew.has(m.New) {
+			errs = append(errs, fmt.Errorf("destination listed more than once: %q", m.New))
+		}
+		seenNew.add(m.New)
+		if _, err := os.Lstat(m.Old); err != nil {
+			errs = append(errs, fmt.Errorf("source missing: %w", err))
+		}
+		if _, err := os.Lstat(m.New); err == nil {
+			errs = append(errs, fmt.Errorf("destination already exists: %q", m.New))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid plan:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
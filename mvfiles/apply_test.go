@@ -0,0 +1,35 @@
+package mvfiles
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidatePlanFoldedDestinations(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.pdf")
+	b := filepath.Join(dir, "b.pdf")
+	for _, name := range []string{a, b} {
+		if err := os.WriteFile(name, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	moves := []Move{
+		{Old: a, New: filepath.Join(dir, "2024", "Report.PDF")},
+		{Old: b, New: filepath.Join(dir, "2024", "report.pdf")},
+	}
+	saved := foldPaths
+	t.Cleanup(func() { foldPaths = saved })
+
+	foldPaths = false
+	if err := validatePlan(moves); err != nil {
+		t.Errorf("with case-sensitive paths: %v", err)
+	}
+	foldPaths = true
+	err := validatePlan(moves)
+	if err == nil || !strings.Contains(err.Error(), "destination listed more than once") {
+		t.Errorf("with case-insensitive paths: got %v, want a duplicate destination", err)
+	}
+}
@@ -0,0 +1,201 @@
+package mvfiles
+
+import (
+	"encoding/binary"
+	"errors"
+	"unicode/utf16"
+)
+
+var errBadPlist = errors.New("bad binary property list")
+
+// bplist reads the subset of Apple's binary property list format
+// used by Spotlight metadata attributes.
+type bplist struct {
+	b          []byte
+	offsets    []uint64
+	refSize    int
+	offsetSize int
+}
+
+// parseBPlistStrings returns the strings in a binary property list
+// holding a string or an array of strings.
+func parseBPlistStrings(b []byte) ([]string, error) {
+	if len(b) < 8+32 || string(b[:8]) != "bplist00" {
+		return nil, errBadPlist
+	}
+	trailer := b[len(b)-32:]
+	p := bplist{
+		b:          b,
+		offsetSize: int(trailer[6]),
+		refSize:    int(trailer[7]),
+	}
+	numObjects := binary.BigEndian.Uint64(trailer[8:])
+	top := binary.BigEndian.Uint64(trailer[16:])
+	tableOffset := binary.BigEndian.Uint64(trailer[24:])
+	if p.offsetSize == 0 || p.refSize == 0 || numObjects > uint64(len(b)) ||
+		tableOffset+numObjects*uint64(p.offsetSize) > uint64(len(b)) {
+		return nil, errBadPlist
+	}
+	for i := range numObjects {
+		start := tableOffset + i*uint64(p.offsetSize)
+		p.offsets = append(p.offsets, readUint(b[start:start+uint64(p.offsetSize)]))
+	}
+	if s, err := p.str(top); err == nil {
+		return []string{s}, nil
+	}
+	refs, err := p.array(top)
+	if err != nil {
+		return nil, err
+	}
+	var strs []string
+	for _, ref := range refs {
+		s, err := p.str(ref)
+		if err != nil {
+			return nil, err
+		}
+		strs = append(strs, s)
+	}
+	return strs, nil
+}
+
+func readUint(b []byte) uint64 {
+	var n uint64
+	for _, c := range b {
+		n = n<<8 | uint64(c)
+	}
+	return n
+}
+
+// object returns the marker of object ref and the bytes following
+// its count, which is stored in the marker or in an integer after it.
+func (p *bplist) object(ref uint64) (marker byte, count int, rest []byte, err error) {
+	if ref >= uint64(len(p.offsets)) || p.offsets[ref] >= uint64(len(p.b)) {
+		return 0, 0, nil, errBadPlist
+	}
+	rest = p.b[p.offsets[ref]:]
+	marker, rest = rest[0]>>4, rest[1:]
+	count = int(p.b[p.offsets[ref]] & 0xf)
+	if count == 0xf {
+		if len(rest) < 1 || rest[0]>>4 != 0x1 {
+			return 0, 0, nil, errBadPlist
+		}
+		size := 1 << (rest[0] & 0xf)
+		if len(rest) < 1+size {
+			return 0, 0, nil, errBadPlist
+		}
+		count = int(readUint(rest[1 : 1+size]))
+		rest = rest[1+size:]
+	}
+	return marker, count, rest, nil
+}
+
+func (p *bplist) str(ref uint64) (string, error) {
+	marker, count, rest, err := p.object(ref)
+	if err != nil {
+		return "", err
+	}
+	switch marker {
+	case 0x5: // ASCII
+		if count > len(rest) {
+			return "", errBadPlist
+		}
+		return string(rest[:count]), nil
+	case 0x6: // UTF-16
+		if 2*count > len(rest) {
+			return "", errBadPlist
+		}
+		units := make([]uint16, count)
+		for i := range units {
+			units[i] = binary.BigEndian.Uint16(rest[2*i:])
+		}
+		return string(utf16.Decode(units)), nil
+	}
+	return "", errBadPlist
+}
+
+func (p *bplist) array(ref uint64) ([]uint64, error) {
+	marker, count, rest, err := p.object(ref)
+	if err != nil {
+		return nil, err
+	}
+	if marker != 0xa || count*p.refSize > len(rest) {
+		return nil, errBadPlist
+	}
+	refs := make([]uint64, count)
+	for i := range refs {
+		refs[i] = readUint(rest[i*p.refSize : (i+1)*p.refSize])
+	}
+	return refs, nil
+}
+
+// encodeBPlistStrings returns a binary property list holding an array of strs.
+func encodeBPlistStrings(strs []string) []byte {
+	refSize := 1
+	if len(strs)+1 > 0xff {
+		refSize = 2
+	}
+	b := []byte("bplist00")
+	offsets := []int{len(b)}
+	b = appendMarker(b, 0xa, len(strs))
+	for i := range strs {
+		b = appendUint(b, uint64(i+1), refSize)
+	}
+	for _, s := range strs {
+		offsets = append(offsets, len(b))
+		if isASCII(s) {
+			b = appendMarker(b, 0x5, len(s))
+			b = append(b, s...)
+			continue
+		}
+		units := utf16.Encode([]rune(s))
+		b = appendMarker(b, 0x6, len(units))
+		for _, u := range units {
+			b = binary.BigEndian.AppendUint16(b, u)
+		}
+	}
+	tableOffset := len(b)
+	offsetSize := 8
+	for _, size := range []int{1, 2, 4} {
+		if tableOffset < 1<<(8*size) {
+			offsetSize = size
+			break
+		}
+	}
+	for _, off := range offsets {
+		b = appendUint(b, uint64(off), offsetSize)
+	}
+	b = append(b, 0, 0, 0, 0, 0, 0, byte(offsetSize), byte(refSize))
+	b = binary.BigEndian.AppendUint64(b, uint64(len(offsets)))
+	b = binary.BigEndian.AppendUint64(b, 0)
+	return binary.BigEndian.AppendUint64(b, uint64(tableOffset))
+}
+
+func appendMarker(b []byte, marker byte, count int) []byte {
+	if count < 0xf {
+		return append(b, marker<<4|byte(count))
+	}
+	b = append(b, marker<<4|0xf)
+	switch {
+	case count <= 0xff:
+		return append(b, 0x10, byte(count))
+	case count <= 0xffff:
+		return binary.BigEndian.AppendUint16(append(b, 0x11), uint16(count))
+	}
+	return binary.BigEndian.AppendUint32(append(b, 0x12), uint32(count))
+}
+
+func appendUint(b []byte, n uint64, size int) []byte {
+	for i := size - 1; i >= 0; i-- {
+		b = append(b, byte(n>>(8*i)))
+	}
+	return b
+}
+
+func isASCII(s string) bool {
+	for i := range len(s) {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
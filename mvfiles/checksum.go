@@ -0,0 +1,110 @@
+package mvfiles
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"text/tabwriter"
+)
+
+// ChecksumSHA256 is the hash for Options.Checksum.
+const ChecksumSHA256 = "sha256"
+
+var checksumAlgorithms = []string{"none", ChecksumSHA256}
+
+// fileChecksum returns the hex encoded SHA-256 of the file at name in src.
+func fileChecksum(src FileSource, name string) (string, error) {
+	f, err := src.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Results of verifying a file
+const (
+	verifyOK      = "ok"
+	verifyChanged = "changed"
+	verifyMissing = "missing"
+)
+
+// verifyResult is the state of a file moved with a checksum.
+type verifyResult struct {
+	Time, Path, Result string
+}
+
+// verify re-hashes the files moved or copied by entries with checksums,
+// following later moves to where they are now. Unless all is set,
+// only the most recent run with checksums is checked.
+func verify(entries []journalEntry, all bool) []verifyResult {
+	run := ""
+	for _, e := range entries {
+		if e.Checksum != "" {
+			run = e.Run
+		}
+	}
+	var results []verifyResult
+	hops := make(map[int]bool)
+	for i, e := range entries {
+		if e.Checksum == "" || hops[i] || (!all && e.Run != run) {
+			continue
+		}
+		if e.Action != actionMove && e.Action != actionCopy {
+			continue
+		}
+		res := verifyResult{Time: e.Time, Path: follow(entries, i, hops), Result: verifyOK}
+		sum, err := fileChecksum(osFiles{}, res.Path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			res.Result = verifyMissing
+		case err != nil:
+			res.Result = err.Error()
+		case sum != e.Checksum:
+			res.Result = verifyChanged
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+// Verify re-hashes the files moved with -checksum and prints the results.
+// It fails if any file is missing or its contents changed.
+func (app *appEnv) Verify(ctx context.Context) error {
+	if app.opts.Journal == "" {
+		return errors.New("no journal file")
+	}
+	entries, err := readJournal(app.opts.Journal)
+	if err != nil {
+		return err
+	}
+	results := verify(entries, app.all)
+	if len(results) == 0 {
+		return errors.New("no moves with checksums in the journal")
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tPATH\tRESULT")
+	bad := 0
+	for _, res := range results {
+		if res.Result != verifyOK {
+			bad++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", res.Time, res.Path, res.Result)
+	}
+	if err = tw.Flush(); err != nil {
+		return err
+	}
+	if bad > 0 {
+		return fmt.Errorf("%d of %d files failed verification", bad, len(results))
+	}
+	return nil
+}
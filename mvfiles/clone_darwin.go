@@ -0,0 +1,35 @@
+package mvfiles
+
+import (
+	"io/fs"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	sysClonefileat = 462 // SYS_clonefileat from <sys/syscall.h>
+	atFDCWD        = -2  // AT_FDCWD from <sys/fcntl.h>
+	cloneNoFollow  = 0x0001
+)
+
+// cloneFile makes a copy-on-write clone of the file or directory tree at src.
+// It fails if the volume does not support cloning or dst is on another volume.
+func cloneFile(src, dst string) error {
+	s, err := syscall.BytePtrFromString(src)
+	if err != nil {
+		return err
+	}
+	d, err := syscall.BytePtrFromString(dst)
+	if err != nil {
+		return err
+	}
+	fd := atFDCWD
+	_, _, errno := syscall.Syscall6(sysClonefileat,
+		uintptr(fd), uintptr(unsafe.Pointer(s)),
+		uintptr(fd), uintptr(unsafe.Pointer(d)),
+		cloneNoFollow, 0)
+	if errno != 0 {
+		return &fs.PathError{Op: "clonefile", Path: src, Err: errno}
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+//go:build !darwin
+
+package mvfiles
+
+import "errors"
+
+func cloneFile(src, dst string) error {
+	return errors.ErrUnsupported
+}
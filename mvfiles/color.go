@@ -0,0 +1,51 @@
+package mvfiles
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+)
+
+// kindColors are ANSI foreground colors for kinds. Every code is the same
+// length, so that tabwriter lines up colored columns.
+var kindColors = []string{
+	"31", "32", "33", "34", "35", "36",
+	"91", "92", "93", "94", "95", "96",
+}
+
+// kindColor returns the ANSI color code for kind,
+// which is the same every time for the same kind.
+func kindColor(kind string) string {
+	if kind == "" {
+		// Directories
+		return "39"
+	}
+	h := fnv.New32a()
+	h.Write([]byte(kind))
+	return kindColors[h.Sum32()%uint32(len(kindColors))]
+}
+
+// colorize wraps s in the ANSI escape codes for color.
+func colorize(color, s string) string {
+	return fmt.Sprintf("\x1b[%sm%s\x1b[39m", color, s)
+}
+
+// useColor reports whether output to f should be in color:
+// f must be a terminal, and neither -no-color nor NO_COLOR can be set.
+func (app *appEnv) useColor(f *os.File) bool {
+	return !app.noColor && os.Getenv("NO_COLOR") == "" && isTerminal(f)
+}
+
+// printPlan writes moves to standard output in app.format.
+// Tables written to a terminal have the kinds in color
+// and end with a summary of the moves.
+func (app *appEnv) printPlan(moves []Move) error {
+	if app.format != formatTable || !app.useColor(os.Stdout) {
+		return writePlan(os.Stdout, app.format, moves)
+	}
+	if err := writeTable(os.Stdout, moves, true); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "\n\x1b[1m%s\x1b[22m\n", app.summarize(moves))
+	return nil
+}
@@ -0,0 +1,106 @@
+package mvfiles
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// defaultCompanions maps the extensions of companion files, like sidecars,
+// to the extensions of the files they belong to, most likely first.
+// "*" matches any file.
+var defaultCompanions = []string{
+	"aae: *",
+	"xmp: *",
+	"srt: mp4 m4v mkv mov avi webm mpeg",
+	"vtt: mp4 m4v mkv mov avi webm mpeg",
+	// Live Photos
+	"mov: heic jpg jpeg",
+	// RAW+JPEG pairs
+	"jpg: cr2 cr3 nef arw dng raf orf rw2",
+	"jpeg: cr2 cr3 nef arw dng raf orf rw2",
+}
+
+// groupCompanions moves companion files, like an .xmp sidecar or the .mov
+// of a Live Photo, along with the file in the same folder with the same name
+// that they belong to, giving them its kind, date, and any new name.
+func (r *runner) groupCompanions(moves []Move) {
+	type key struct{ dir, name string }
+	byName := make(map[key]int)
+	byStem := make(map[key][]int)
+	for i, m := range moves {
+		if m.Kind == "" {
+			continue
+		}
+		dir, name := filepath.Dir(m.Old), strings.ToLower(filepath.Base(m.Old))
+		byName[key{dir, name}] = i
+		stem := strings.TrimSuffix(name, filepath.Ext(name))
+		byStem[key{dir, stem}] = append(byStem[key{dir, stem}], i)
+	}
+	isCompanion := func(i int) bool {
+		_, ok := r.Kinds.companions[lowerExt(moves[i].Old)]
+		return ok
+	}
+	// primary returns the index of the move that moves[i] belongs to, or -1.
+	primary := func(i int) int {
+		exts, ok := r.Kinds.companions[lowerExt(moves[i].Old)]
+		if !ok || moves[i].Kind == "" {
+			return -1
+		}
+		dir, name := filepath.Dir(moves[i].Old), strings.ToLower(filepath.Base(moves[i].Old))
+		stem := strings.TrimSuffix(name, filepath.Ext(name))
+		anyExt := slices.Contains(exts, "*")
+		// Sidecars can be named after the whole name, like photo.cr2.xmp
+		if j, ok := byName[key{dir, stem}]; ok && anyExt {
+			return j
+		}
+		for _, ext := range exts {
+			if j, ok := byName[key{dir, stem + "." + ext}]; ok {
+				return j
+			}
+		}
+		if !anyExt {
+			return -1
+		}
+		// Prefer a file that isn't a companion itself
+		best := -1
+		for _, j := range byStem[key{dir, stem}] {
+			if j != i && (best < 0 || isCompanion(best) && !isCompanion(j)) {
+				best = j
+			}
+		}
+		return best
+	}
+	parents := make([]int, len(moves))
+	for i := range moves {
+		parents[i] = primary(i)
+	}
+	for i := range moves {
+		// Follow chains, like an .aae belonging to a .mov belonging to a .heic
+		j := parents[i]
+		for steps := 0; j >= 0 && parents[j] >= 0 && steps < len(moves); steps++ {
+			j = parents[j]
+		}
+		if j < 0 || j == i {
+			continue
+		}
+		p := moves[j]
+		name, newName := filepath.Base(moves[i].Old), filepath.Base(p.New)
+		if base := filepath.Base(p.Old); len(name) > len(base) && strings.EqualFold(name[:len(base)+1], base+".") {
+			newName += name[len(base):]
+		} else {
+			newName = strings.TrimSuffix(newName, filepath.Ext(newName)) + filepath.Ext(name)
+		}
+		r.Logger.Debug("moving companion", "old", moves[i].Old, "with", p.Old)
+		moves[i].New = filepath.Join(filepath.Dir(p.New), newName)
+		if isPhotosImport(p.New) {
+			moves[i].New = PhotosPrefix + name
+		}
+		moves[i].Kind, moves[i].Date = p.Kind, p.Date
+	}
+}
+
+// lowerExt returns the extension of name in lowercase, without the leading dot.
+func lowerExt(name string) string {
+	return strings.ToLower(strings.TrimPrefix(filepath.Ext(name), "."))
+}
@@ -0,0 +1,252 @@
+package mvfiles
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Shells that completion scripts can be written for
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// The completion command lists every command, including itself,
+// so it is added once commands is initialized.
+func init() {
+	commands = append(commands, command{
+		name:    "completion",
+		args:    " <" + strings.Join(completionShells, "|") + ">",
+		nargs:   1,
+		summary: "write a shell completion script",
+		flags: func(app *appEnv, fl *flag.FlagSet) {
+			app.kindsFlag(fl)
+		},
+		run: (*appEnv).Completion,
+	})
+}
+
+// completion is what a completion script completes.
+type completion struct {
+	commands []completionCommand
+	kinds    []string
+	profiles []string
+}
+
+type completionCommand struct {
+	name    string
+	summary string
+	flags   []*flag.Flag
+}
+
+// kindOptions and profileOptions are completed with kinds and profiles.
+var (
+	kindOptions    = []string{"only-kind", "skip-kind"}
+	profileOptions = []string{"profile"}
+)
+
+// Completion writes a completion script for the shell in app.args[0].
+// The kinds and profiles in it are the ones in -kinds and -config.
+func (app *appEnv) Completion(ctx context.Context) error {
+	c := completion{profiles: profileNames(app.configFile)}
+	kinds := app.opts.Kinds
+	if kinds == nil {
+		kinds = NewKinds()
+	}
+	c.kinds = kinds.names()
+	for _, cmd := range commands {
+		var (
+			cmdApp appEnv
+			cc     = completionCommand{name: cmd.name, summary: cmd.summary}
+		)
+		fl := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+		cmd.flags(&cmdApp, fl)
+		cmdApp.commonFlags(fl)
+		fl.VisitAll(func(f *flag.Flag) {
+			cc.flags = append(cc.flags, f)
+		})
+		c.commands = append(c.commands, cc)
+	}
+	switch shell := app.args[0]; shell {
+	case "bash":
+		c.writeBash(os.Stdout)
+	case "zsh":
+		c.writeZsh(os.Stdout)
+	case "fish":
+		c.writeFish(os.Stdout)
+	default:
+		return fmt.Errorf("unknown shell %q: want one of %s", shell, strings.Join(completionShells, ", "))
+	}
+	return nil
+}
+
+// isBoolFlag reports whether f takes no value.
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// flagPatterns returns a shell case pattern matching names as options.
+func flagPatterns(names []string) string {
+	var pats []string
+	for _, name := range names {
+		pats = append(pats, "-"+name, "--"+name)
+	}
+	return strings.Join(pats, "|")
+}
+
+// words quotes each of list as a shell word and joins them with spaces.
+func words(list []string) string {
+	quoted := make([]string, len(list))
+	for i, s := range list {
+		quoted[i] = shellQuote(s)
+	}
+	return strings.Join(quoted, " ")
+}
+
+func (c completion) names() []string {
+	var names []string
+	for _, cmd := range c.commands {
+		names = append(names, cmd.name)
+	}
+	return names
+}
+
+func (cc completionCommand) flagNames() []string {
+	var names []string
+	for _, f := range cc.flags {
+		names = append(names, "-"+f.Name)
+	}
+	return names
+}
+
+func (c completion) writeBash(w io.Writer) {
+	fmt.Fprintf(w, `# bash completion for scooter
+# Add to ~/.bashrc: source <(scooter completion bash)
+
+_scooter() {
+	local cur=${COMP_WORDS[COMP_CWORD]} prev=${COMP_WORDS[COMP_CWORD-1]} cmd=move
+	case $prev in
+	%s)
+		COMPREPLY=($(compgen -W %s -- "$cur"))
+		return
+		;;
+	%s)
+		COMPREPLY=($(compgen -W %s -- "$cur"))
+		return
+		;;
+	esac
+	if [[ $COMP_CWORD -gt 1 ]]; then
+		case ${COMP_WORDS[1]} in
+		%s) cmd=${COMP_WORDS[1]} ;;
+		esac
+	elif [[ $cur != -* ]]; then
+		COMPREPLY=($(compgen -W %s -- "$cur"))
+		return
+	fi
+	if [[ $cur == -* ]]; then
+		case $cmd in
+`, flagPatterns(kindOptions), shellQuote(words(c.kinds)),
+		flagPatterns(profileOptions), shellQuote(words(c.profiles)),
+		strings.Join(c.names(), "|"), shellQuote(words(c.names())))
+	for _, cmd := range c.commands {
+		fmt.Fprintf(w, "\t\t%s) COMPREPLY=($(compgen -W %s -- \"$cur\")) ;;\n",
+			cmd.name, shellQuote(words(cmd.flagNames())))
+	}
+	fmt.Fprint(w, `		esac
+	fi
+}
+
+complete -o default -F _scooter scooter
+`)
+}
+
+func (c completion) writeZsh(w io.Writer) {
+	fmt.Fprintf(w, `#compdef scooter
+# Add to ~/.zshrc after compinit: source <(scooter completion zsh)
+
+_scooter() {
+	local -a cmds kinds profiles flags
+	local cmd=move
+	cmds=(
+`)
+	for _, cmd := range c.commands {
+		fmt.Fprintf(w, "\t\t%s\n", shellQuote(cmd.name+":"+cmd.summary))
+	}
+	fmt.Fprintf(w, `	)
+	kinds=(%s)
+	profiles=(%s)
+	case $words[CURRENT-1] in
+	(%s)
+		compadd -a kinds
+		return
+		;;
+	(%s)
+		compadd -a profiles
+		return
+		;;
+	esac
+	case $words[2] in
+	(%s) (( CURRENT > 2 )) && cmd=$words[2] ;;
+	esac
+	if [[ $words[CURRENT] == -* ]]; then
+		case $cmd in
+`, words(c.kinds), words(c.profiles),
+		flagPatterns(kindOptions), flagPatterns(profileOptions),
+		strings.Join(c.names(), "|"))
+	for _, cmd := range c.commands {
+		fmt.Fprintf(w, "\t\t(%s) flags=(%s) ;;\n", cmd.name, words(cmd.flagNames()))
+	}
+	fmt.Fprint(w, `		esac
+		compadd -a flags
+		return
+	fi
+	if (( CURRENT == 2 )); then
+		_describe command cmds
+	fi
+	_files
+}
+
+compdef _scooter scooter
+`)
+}
+
+// fishQuote quotes s as a fish string.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+func (c completion) writeFish(w io.Writer) {
+	fmt.Fprint(w, `# fish completion for scooter
+# Save to ~/.config/fish/completions/scooter.fish: scooter completion fish > ~/.config/fish/completions/scooter.fish
+
+`)
+	names := strings.Join(c.names(), " ")
+	for _, cmd := range c.commands {
+		fmt.Fprintf(w, "complete -c scooter -n '__fish_use_subcommand' -f -a %s -d %s\n",
+			cmd.name, fishQuote(cmd.summary))
+	}
+	for _, cmd := range c.commands {
+		cond := fmt.Sprintf("'__fish_seen_subcommand_from %s'", cmd.name)
+		if cmd.name == "move" {
+			// move is the default command
+			cond = fmt.Sprintf("'not __fish_seen_subcommand_from %s'", names)
+		}
+		for _, f := range cmd.flags {
+			_, usage := flag.UnquoteUsage(f)
+			args := ""
+			switch {
+			case isBoolFlag(f):
+			case slices.Contains(kindOptions, f.Name):
+				args = " -x -a " + fishQuote(strings.Join(c.kinds, " "))
+			case slices.Contains(profileOptions, f.Name):
+				args = " -x -a " + fishQuote(strings.Join(c.profiles, " "))
+			default:
+				args = " -r"
+			}
+			fmt.Fprintf(w, "complete -c scooter -n %s -o %s%s -d %s\n", cond, f.Name, args, fishQuote(usage))
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package mvfiles
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"maps"
+	"slices"
+	"strconv"
+
+	"github.com/BurntSushi/toml"
+)
+
+// config is the format of the config file:
+//
+//	[profiles.downloads]
+//	dir = "~/Downloads"
+//	only-kind = ["image", "video"]
+//	on-conflict = "skip"
+//
+//	[profiles.desktop]
+//	dir = "~/Desktop"
+//	template = "{{.Year}}/{{.Kind}}"
+//
+// The keys of a profile are the names of command line options.
+type config struct {
+	Profiles map[string]map[string]any `toml:"profiles"`
+}
+
+// builtinProfiles are used when the config file has no profile of their name.
+var builtinProfiles = map[string]map[string]any{
+	"airdrop": {"dir": "~/Downloads", "airdrop": true},
+}
+
+func (app *appEnv) profileFlags(fl *flag.FlagSet) {
+	fl.StringVar(&app.configFile, "config", defaultConfigPath("config.toml"), "TOML `file` with profiles")
+	fl.StringVar(&app.profile, "profile", "", "`name` of a profile in -config to use for options that aren't set")
+}
+
+// applyProfile sets the options in app.profile that weren't set
+// on the command line or by environment variables.
+func (app *appEnv) applyProfile(fl *flag.FlagSet) error {
+	if app.profile == "" {
+		return nil
+	}
+	var conf config
+	md, err := toml.DecodeFile(expandHome(app.configFile), &conf)
+	builtin, isBuiltin := builtinProfiles[app.profile]
+	if errors.Is(err, fs.ErrNotExist) && !isBuiltin {
+		return fmt.Errorf("no config file for profile %q: %w", app.profile, err)
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return fmt.Errorf("loading config: unknown key %q in %q", undecoded[0], app.configFile)
+	}
+	profile, ok := conf.Profiles[app.profile]
+	if !ok && isBuiltin {
+		profile, ok = builtin, true
+	}
+	if !ok {
+		return fmt.Errorf("no profile %q in %q", app.profile, app.configFile)
+	}
+	set := make(map[string]bool)
+	fl.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	for _, name := range slices.Sorted(maps.Keys(profile)) {
+		if set[name] {
+			continue
+		}
+		if fl.Lookup(name) == nil {
+			if !isFlag(name) {
+				return fmt.Errorf("profile %q: unknown option %q", app.profile, name)
+			}
+			// Meant for another command
+			continue
+		}
+		values, ok := profile[name].([]any)
+		if !ok {
+			values = []any{profile[name]}
+		}
+		for _, v := range values {
+			var s string
+			switch v := v.(type) {
+			case string:
+				s = expandHome(v)
+			case bool:
+				s = strconv.FormatBool(v)
+			case int64:
+				s = strconv.FormatInt(v, 10)
+			default:
+				return fmt.Errorf("profile %q: bad value for %q: %v", app.profile, name, v)
+			}
+			if err := fl.Set(name, s); err != nil {
+				return fmt.Errorf("profile %q: %s: %w", app.profile, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// profileNames returns the names of the profiles in the config file name
+// and the built-in profiles, sorted.
+func profileNames(name string) []string {
+	var conf config
+	_, _ = toml.DecodeFile(expandHome(name), &conf)
+	names := slices.AppendSeq(slices.Collect(maps.Keys(conf.Profiles)), maps.Keys(builtinProfiles))
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// isFlag reports whether any command has an option called name.
+func isFlag(name string) bool {
+	for _, cmd := range commands {
+		var app appEnv
+		fl := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+		cmd.flags(&app, fl)
+		if fl.Lookup(name) != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package mvfiles
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"syscall"
+)
+
+// Strategies for Options.OnConflict
+const (
+	ConflictSkip      = "skip"      // leave the file in place
+	ConflictOverwrite = "overwrite" // replace the existing file
+	ConflictRename    = "rename"    // add a suffix like " (1)" to the name
+	ConflictError     = "error"     // stop with an error
+	ConflictTrash     = "trash"     // move the existing file to the Trash
+)
+
+var conflictStrategies = []string{
+	ConflictSkip, ConflictOverwrite, ConflictRename, ConflictError, ConflictTrash,
+}
+
+// resolveConflict returns the destination to use for newpath under strategy
+// or the empty string if the move should be skipped.
+// Paths in claimed are treated as existing, as are paths in src.
+func resolveConflict(src FileSource, strategy, newpath string, claimed pathSet) (string, error) {
+	if claimed.has(newpath) {
+		if strategy == ConflictError {
+			return "", fmt.Errorf("destination planned for more than one file: %q", newpath)
+		}
+	} else {
+		_, err := src.Lstat(newpath)
+		// If a parent is not a directory, creating it will fail with a clearer error
+		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ENOTDIR) {
+			return newpath, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	switch strategy {
+	case ConflictSkip:
+		return "", nil
+	case ConflictOverwrite, ConflictTrash:
+		return newpath, nil
+	case ConflictRename:
+		return freeName(src, newpath, claimed)
+	}
+	return "", fmt.Errorf("destination already exists: %q", newpath)
+}
+
+// freeName returns the first unused name in the style of "name (1).ext"
+// that is not in claimed or src.
+func freeName(src FileSource, name string, claimed pathSet) (string, error) {
+	base, ext := splitExt(name)
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, i, ext)
+		if claimed.has(candidate) {
+			continue
+		}
+		_, err := src.Lstat(candidate)
+		if errors.Is(err, fs.ErrNotExist) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
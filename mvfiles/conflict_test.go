@@ -0,0 +1,97 @@
+package mvfiles
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPlanConflictStrategies(t *testing.T) {
+	date := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		strategy string
+		want     map[string]string
+		err      string
+	}{
+		{ConflictSkip, map[string]string{
+			"/in/new.pdf": "/in/2024/03/doc/new.pdf",
+		}, ""},
+		{ConflictOverwrite, map[string]string{
+			"/in/a.pdf":   "/in/2024/03/doc/a.pdf",
+			"/in/new.pdf": "/in/2024/03/doc/new.pdf",
+		}, ""},
+		{ConflictRename, map[string]string{
+			"/in/a.pdf":   "/in/2024/03/doc/a (1).pdf",
+			"/in/new.pdf": "/in/2024/03/doc/new.pdf",
+		}, ""},
+		{ConflictTrash, map[string]string{
+			"/in/a.pdf":   "/in/2024/03/doc/a.pdf",
+			"/in/new.pdf": "/in/2024/03/doc/new.pdf",
+		}, ""},
+		{ConflictError, nil, "destination already exists"},
+	} {
+		t.Run(tc.strategy, func(t *testing.T) {
+			m := newTestFS(t, map[string]string{
+				"/in/a.pdf":             "new",
+				"/in/new.pdf":           "",
+				"/in/2024/03/doc/a.pdf": "existing",
+			})
+			meta := added(map[string]time.Time{"/in/a.pdf": date, "/in/new.pdf": date})
+			opts := fakeOptions(m, meta, date)
+			opts.OnConflict = tc.strategy
+			moves, err := Plan(filepath.FromSlash("/in"), opts)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Errorf("got %v, want an error about %q", err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkPlan(t, moves, tc.want)
+		})
+	}
+}
+
+func TestPlanClaimedDestinations(t *testing.T) {
+	date := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		strategy string
+		moves    int
+		err      string
+	}{
+		{ConflictSkip, 1, ""},
+		{ConflictRename, 2, ""},
+		{ConflictError, 0, "destination planned for more than one file"},
+	} {
+		t.Run(tc.strategy, func(t *testing.T) {
+			m := newTestFS(t, map[string]string{"/in/x/a.pdf": "1", "/in/y/a.pdf": "2"})
+			meta := added(map[string]time.Time{"/in/x/a.pdf": date, "/in/y/a.pdf": date})
+			opts := fakeOptions(m, meta, date)
+			opts.Recursive = true
+			opts.OnConflict = tc.strategy
+			moves, err := Plan(filepath.FromSlash("/in"), opts)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Errorf("got %v, want an error about %q", err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(moves) != tc.moves {
+				t.Errorf("planned %v, want %d moves", planned(moves), tc.moves)
+			}
+			seen := make(map[string]bool)
+			for _, mv := range moves {
+				if seen[mv.New] {
+					t.Errorf("destination planned twice: %s", mv.New)
+				}
+				seen[mv.New] = true
+			}
+		})
+	}
+}
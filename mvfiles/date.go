@@ -0,0 +1,190 @@
+package mvfiles
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+var dateSources = map[string]func(path string) (time.Time, error){
+	"added":     getDateAdded,
+	"birthtime": getBirthTime,
+	"filename":  getFilenameDate,
+	"mtime":     getModTime,
+}
+
+var defaultDateSources = []string{"added", "birthtime", "mtime"}
+
+func parseDateSources(s string) ([]string, error) {
+	sources := strings.Split(s, ",")
+	for i, source := range sources {
+		source = strings.TrimSpace(source)
+		if _, ok := dateSources[source]; !ok {
+			return nil, fmt.Errorf("unknown date source %q", source)
+		}
+		sources[i] = source
+	}
+	return slices.Compact(sources), nil
+}
+
+// getDate returns the date for a file of the given kind. With PhotoDate exif,
+// images use their capture date, if any, and with DocDate metadata,
+// documents use their embedded creation date, if any.
+// Email messages always use their Date header, if any. Otherwise, it returns the date from
+// the first of r.DateSources that works, retrying transient errors, or with
+// r.OnDateError mtime, the modification time.
+func (r *runner) getDate(path, kind string) (time.Time, error) {
+	// Reading the contents of iCloud placeholders would download them
+	if isPlaceholder(path) {
+		kind = ""
+	}
+	if r.PhotoDate == "exif" && kind == "image" {
+		t, err := getEXIFDate(r.Source, path)
+		if err == nil {
+			return t, nil
+		}
+		r.Logger.Debug("exif date unavailable", "path", path, "error", err)
+	}
+	if kind == "email" {
+		t, err := getEmailDate(r.Source, path)
+		if err == nil {
+			return t, nil
+		}
+		r.Logger.Debug("email date unavailable", "path", path, "error", err)
+	}
+	if r.DocDate == "metadata" && kind != "" {
+		t, err := getDocDate(r.Source, path)
+		if err == nil {
+			return t, nil
+		}
+		r.Logger.Debug("document date unavailable", "path", path, "error", err)
+	}
+	var errs []error
+	for _, source := range r.DateSources {
+		if t, ok := r.datesAdded[path]; ok && source == "added" {
+			return t, nil
+		}
+		t, err := r.lookupDate(source, path)
+		if err == nil {
+			return t, nil
+		}
+		r.Logger.Debug("date unavailable", "source", source, "path", path, "error", err)
+		errs = append(errs, err)
+	}
+	if r.OnDateError == DateErrorMtime && !slices.Contains(r.DateSources, "mtime") {
+		t, err := r.lookupDate("mtime", path)
+		if err == nil {
+			r.Logger.Warn("using modification time", "path", path, "error", errors.Join(errs...))
+			return t, nil
+		}
+		errs = append(errs, err)
+	}
+	return time.Time{}, &MetadataError{path, errors.Join(errs...)}
+}
+
+// MetadataError is returned by Plan and the other planning functions
+// when none of the date sources work for a file.
+type MetadataError struct {
+	Path string
+	Err  error
+}
+
+func (e *MetadataError) Error() string {
+	return fmt.Sprintf("no date for %q: %v", e.Path, e.Err)
+}
+
+func (e *MetadataError) Unwrap() error { return e.Err }
+
+// prefetchDatesAdded looks up the dates added of paths a folder at a time,
+// which is much faster than looking them up one by one.
+func (r *runner) prefetchDatesAdded(paths []string) {
+	dirs := make(map[string]bool)
+	for _, path := range paths {
+		dirs[filepath.Dir(path)] = true
+	}
+	r.datesAdded = make(map[string]time.Time, len(paths))
+	for dir := range dirs {
+		dates, err := getDatesAdded(dir)
+		if errors.Is(err, errors.ErrUnsupported) {
+			return
+		}
+		if err != nil {
+			r.Logger.Debug("listing dates added", "dir", dir, "error", err)
+			continue
+		}
+		for name, t := range dates {
+			r.datesAdded[filepath.Join(dir, name)] = t
+		}
+	}
+}
+
+// getDirDate returns the date of the newest or oldest file in the directory
+// at path and its subdirectories, according to r.DirDate.
+// It returns date if the directory has no files.
+func (r *runner) getDirDate(path string, date time.Time) (time.Time, error) {
+	var found time.Time
+	err := fs.WalkDir(subFS{r.Source, path}, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name != "." && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		name = filepath.Join(path, filepath.FromSlash(name))
+		t, err := r.getDate(name, "")
+		if err != nil {
+			r.Logger.Debug("date unavailable", "path", name, "error", err)
+			return nil
+		}
+		if found.IsZero() ||
+			r.DirDate == "newest" && t.After(found) ||
+			r.DirDate == "oldest" && t.Before(found) {
+			found = t
+		}
+		return nil
+	})
+	if err != nil || found.IsZero() {
+		return date, err
+	}
+	return found, nil
+}
+
+// filenameDate matches dates in names, like 2024-11-02, 2024_11_02,
+// or 20241102, optionally followed by a time, like 14.30.00 or 143000.
+var filenameDate = regexp.MustCompile(
+	`(?:^|\D)((?:19|20)\d\d)[-_.]?(\d\d)[-_.]?(\d\d)(?:[ T_-](\d\d)[.:_-]?(\d\d)[.:_-]?(\d\d))?(?:\D|$)`)
+
+// getFilenameDate returns the first valid date in the name of path.
+func getFilenameDate(path string) (time.Time, error) {
+	name := filepath.Base(path)
+	for _, m := range filenameDate.FindAllStringSubmatch(name, -1) {
+		layout, value := "2006 01 02", m[1]+" "+m[2]+" "+m[3]
+		if m[4] != "" {
+			layout, value = layout+" 15 04 05", value+" "+m[4]+" "+m[5]+" "+m[6]
+		}
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("no date in name %q", name)
+}
+
+func getModTime(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
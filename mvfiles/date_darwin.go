@@ -0,0 +1,134 @@
+package mvfiles
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/fs"
+	"os"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// getattrlist(2) constants from <sys/attr.h>
+const (
+	attrBitMapCount      = 5
+	attrCmnName          = 0x00000001
+	attrCmnAddedTime     = 0x10000000
+	attrCmnError         = 0x20000000
+	attrCmnReturnedAttrs = 0x80000000
+	fsoptNoFollow        = 0x00000001
+)
+
+const sysGetattrlistbulk = 461 // SYS_getattrlistbulk from <sys/syscall.h>
+
+type attrList struct {
+	bitmapCount uint16
+	_           uint16
+	commonAttr  uint32
+	volAttr     uint32
+	dirAttr     uint32
+	fileAttr    uint32
+	forkAttr    uint32
+}
+
+// getDateAdded returns when path was put in its folder
+// using getattrlist(2) with ATTR_CMN_ADDEDTIME.
+func getDateAdded(path string) (time.Time, error) {
+	p, err := syscall.BytePtrFromString(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	req := attrList{
+		bitmapCount: attrBitMapCount,
+		commonAttr:  attrCmnReturnedAttrs | attrCmnAddedTime,
+	}
+	var buf struct {
+		length   uint32
+		returned [attrBitMapCount]uint32
+		added    syscall.Timespec
+	}
+	_, _, errno := syscall.Syscall6(syscall.SYS_GETATTRLIST,
+		uintptr(unsafe.Pointer(p)), uintptr(unsafe.Pointer(&req)),
+		uintptr(unsafe.Pointer(&buf)), unsafe.Sizeof(buf), fsoptNoFollow, 0)
+	if errno != 0 {
+		return time.Time{}, &fs.PathError{Op: "getattrlist", Path: path, Err: errno}
+	}
+	if buf.returned[0]&attrCmnAddedTime == 0 {
+		return time.Time{}, fmt.Errorf("no date added for %q", path)
+	}
+	return time.Unix(buf.added.Unix()), nil
+}
+
+// getDatesAdded returns the dates added of the entries of dir by name,
+// reading them in bulk with getattrlistbulk(2).
+func getDatesAdded(dir string) (map[string]time.Time, error) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	req := attrList{
+		bitmapCount: attrBitMapCount,
+		commonAttr:  attrCmnReturnedAttrs | attrCmnError | attrCmnName | attrCmnAddedTime,
+	}
+	dates := make(map[string]time.Time)
+	buf := make([]byte, 256*1024)
+	for {
+		n, _, errno := syscall.Syscall6(sysGetattrlistbulk,
+			f.Fd(), uintptr(unsafe.Pointer(&req)),
+			uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)), 0, 0)
+		if errno != 0 {
+			return nil, &fs.PathError{Op: "getattrlistbulk", Path: dir, Err: errno}
+		}
+		if n == 0 {
+			return dates, nil
+		}
+		entry := buf
+		for range n {
+			length := binary.LittleEndian.Uint32(entry)
+			parseBulkEntry(entry[:length], dates)
+			entry = entry[length:]
+		}
+	}
+}
+
+// parseBulkEntry adds the name and date added in an entry
+// returned by getattrlistbulk to dates.
+// The attributes are packed in the order of their bits,
+// after the length and the set of attributes returned.
+func parseBulkEntry(entry []byte, dates map[string]time.Time) {
+	le := binary.LittleEndian
+	returned := le.Uint32(entry[4:])
+	pos := 4 + 4*attrBitMapCount
+	if returned&attrCmnError != 0 {
+		if le.Uint32(entry[pos:]) != 0 {
+			return
+		}
+		pos += 4
+	}
+	if returned&attrCmnName == 0 || returned&attrCmnAddedTime == 0 {
+		return
+	}
+	// An attrreference_t holds the offset of the name from itself and its length
+	offset := int(int32(le.Uint32(entry[pos:])))
+	length := int(le.Uint32(entry[pos+4:]))
+	name := string(bytes.TrimRight(entry[pos+offset:pos+offset+length], "\x00"))
+	pos += 8
+	sec := int64(le.Uint64(entry[pos:]))
+	nsec := int64(le.Uint64(entry[pos+8:]))
+	dates[name] = time.Unix(sec, nsec)
+}
+
+func getBirthTime(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return time.Time{}, fmt.Errorf("no birth time for %q", path)
+	}
+	return time.Unix(st.Birthtimespec.Unix()), nil
+}
@@ -0,0 +1,59 @@
+package mvfiles
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// statx(2) constants from <linux/fcntl.h> and <linux/stat.h>
+const (
+	atFDCWD           = -100
+	atSymlinkNoFollow = 0x100
+	statxBtime        = 0x800
+)
+
+// Linux doesn't record when a file was put in its folder,
+// so getDateAdded uses its birth time, if any, or else its modification time.
+func getDateAdded(path string) (time.Time, error) {
+	if t, err := getBirthTime(path); err == nil {
+		return t, nil
+	}
+	return getModTime(path)
+}
+
+// getBirthTime returns the creation time of path from statx(2).
+// Not every file system records it.
+func getBirthTime(path string) (time.Time, error) {
+	if sysStatx == 0 {
+		return time.Time{}, fmt.Errorf("no birth time for %q: %w", path, errors.ErrUnsupported)
+	}
+	p, err := syscall.BytePtrFromString(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var buf [256]byte // struct statx
+	fd := atFDCWD
+	_, _, errno := syscall.Syscall6(sysStatx,
+		uintptr(fd), uintptr(unsafe.Pointer(p)), atSymlinkNoFollow, statxBtime,
+		uintptr(unsafe.Pointer(&buf[0])), 0)
+	if errno != 0 {
+		return time.Time{}, &fs.PathError{Op: "statx", Path: path, Err: errno}
+	}
+	ne := binary.NativeEndian
+	if ne.Uint32(buf[0:])&statxBtime == 0 {
+		return time.Time{}, fmt.Errorf("no birth time for %q", path)
+	}
+	// stx_btime is a struct statx_timestamp at offset 80
+	sec := int64(ne.Uint64(buf[80:]))
+	nsec := int64(ne.Uint32(buf[88:]))
+	return time.Unix(sec, nsec), nil
+}
+
+func getDatesAdded(dir string) (map[string]time.Time, error) {
+	return nil, errors.ErrUnsupported
+}
@@ -0,0 +1,90 @@
+package mvfiles
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPlanDateErrors(t *testing.T) {
+	date := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	mtime := time.Date(2022, 7, 8, 0, 0, 0, 0, time.UTC)
+	newMeta := func() *FakeMetadata {
+		meta := added(map[string]time.Time{"/in/a.pdf": date})
+		undated := filepath.FromSlash("/in/undated.pdf")
+		meta.Dates[undated] = map[string]time.Time{"mtime": mtime}
+		meta.Errors = map[string]map[string]error{undated: {"added": fs.ErrPermission}}
+		return meta
+	}
+	for _, tc := range []struct {
+		mode string
+		want map[string]string
+	}{
+		{DateErrorMtime, map[string]string{
+			"/in/a.pdf":       "/in/2024/03/doc/a.pdf",
+			"/in/undated.pdf": "/in/2022/07/doc/undated.pdf",
+		}},
+		{DateErrorSkip, map[string]string{
+			"/in/a.pdf": "/in/2024/03/doc/a.pdf",
+		}},
+	} {
+		t.Run(tc.mode, func(t *testing.T) {
+			m := newTestFS(t, map[string]string{"/in/a.pdf": "", "/in/undated.pdf": ""})
+			opts := fakeOptions(m, newMeta(), date)
+			opts.OnDateError = tc.mode
+			moves, err := Plan(filepath.FromSlash("/in"), opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkPlan(t, moves, tc.want)
+		})
+	}
+	t.Run(DateErrorFail, func(t *testing.T) {
+		m := newTestFS(t, map[string]string{"/in/a.pdf": "", "/in/undated.pdf": ""})
+		opts := fakeOptions(m, newMeta(), date)
+		opts.OnDateError = DateErrorFail
+		_, err := Plan(filepath.FromSlash("/in"), opts)
+		var merr *MetadataError
+		if !errors.As(err, &merr) || merr.Path != filepath.FromSlash("/in/undated.pdf") {
+			t.Fatalf("got %v, want a *MetadataError for undated.pdf", err)
+		}
+		if !errors.Is(err, fs.ErrPermission) {
+			t.Errorf("got %v, want it to wrap the error from the date source", err)
+		}
+	})
+	t.Run("no mtime", func(t *testing.T) {
+		m := newTestFS(t, map[string]string{"/in/undated.pdf": ""})
+		meta := &FakeMetadata{Errors: map[string]map[string]error{
+			filepath.FromSlash("/in/undated.pdf"): {"": fs.ErrPermission},
+		}}
+		_, err := Plan(filepath.FromSlash("/in"), fakeOptions(m, meta, date))
+		var merr *MetadataError
+		if !errors.As(err, &merr) {
+			t.Errorf("got %v, want a *MetadataError when mtime fails too", err)
+		}
+	})
+}
+
+func TestPlanDateSources(t *testing.T) {
+	date := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	m := newTestFS(t, map[string]string{"/in/a.pdf": "", "/in/b.pdf": ""})
+	meta := &FakeMetadata{
+		Dates: map[string]map[string]time.Time{
+			filepath.FromSlash("/in/a.pdf"): {"birthtime": date, "mtime": date.AddDate(1, 0, 0)},
+			filepath.FromSlash("/in/b.pdf"): {"added": date.AddDate(-1, 0, 0), "birthtime": date},
+		},
+	}
+	opts := fakeOptions(m, meta, date)
+	opts.DateSources = []string{"added", "birthtime", "mtime"}
+	opts.OnDateError = DateErrorFail
+	moves, err := Plan(filepath.FromSlash("/in"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkPlan(t, moves, map[string]string{
+		"/in/a.pdf": "/in/2024/03/doc/a.pdf",
+		"/in/b.pdf": "/in/2023/03/doc/b.pdf",
+	})
+}
@@ -0,0 +1,32 @@
+package mvfiles
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+)
+
+// Windows doesn't record when a file was put in its folder, but copying
+// or downloading a file sets its creation time, so getDateAdded uses that.
+func getDateAdded(path string) (time.Time, error) {
+	return getBirthTime(path)
+}
+
+// getBirthTime returns the NTFS creation time of path.
+func getBirthTime(path string) (time.Time, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	data, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	if !ok {
+		return time.Time{}, fmt.Errorf("no creation time for %q", path)
+	}
+	return time.Unix(0, data.CreationTime.Nanoseconds()), nil
+}
+
+func getDatesAdded(dir string) (map[string]time.Time, error) {
+	return nil, errors.ErrUnsupported
+}
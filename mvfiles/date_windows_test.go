@@ -0,0 +1,31 @@
+package mvfiles
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGetBirthTime(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "file.txt")
+	before := time.Now().Add(-time.Second)
+	if err := os.WriteFile(name, []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	after := time.Now().Add(time.Second)
+	// Changing the modification time leaves the creation time alone
+	if err := os.Chtimes(name, time.Time{}, time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := getBirthTime(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Before(before) || got.After(after) {
+		t.Errorf("getBirthTime = %v, want between %v and %v", got, before, after)
+	}
+	if _, err = getBirthTime(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("getBirthTime of a missing file succeeded")
+	}
+}
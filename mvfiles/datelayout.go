@@ -0,0 +1,128 @@
+package mvfiles
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateLayouts are the named layouts for -date-layout,
+// which choose the default template.
+var dateLayouts = map[string]string{
+	"month":      "{{.Year}}/{{.Month}}/{{.Kind}}",                // 2025/05
+	"month-name": "{{.Year}}/{{.Month}}-{{.MonthName}}/{{.Kind}}", // 2025/05-May
+	"year-month": "{{.Year}}-{{.Month}}/{{.Kind}}",                // 2025-05
+	"week":       "{{.WeekYear}}/{{.Week}}/{{.Kind}}",             // 2025/W18
+	"quarter":    "{{.Year}}/{{.Quarter}}/{{.Kind}}",              // 2025/Q2
+}
+
+var dateLayoutNames = []string{"month", "month-name", "year-month", "week", "quarter"}
+
+// Patterns for the folders made by the date layouts
+var (
+	yearDirPattern    = regexp.MustCompile(`^(20\d\d)(?:-(\d\d))?$`)
+	monthDirPattern   = regexp.MustCompile(`^(\d\d)(?:-.+)?$`)
+	weekDirPattern    = regexp.MustCompile(`^W(\d\d)$`)
+	quarterDirPattern = regexp.MustCompile(`^Q([1-4])$`)
+)
+
+// isYearDir reports whether name looks like a year folder, e.g. 2024,
+// or a year and month folder, e.g. 2024-05.
+func isYearDir(name string) bool {
+	return yearDirPattern.MatchString(name)
+}
+
+// isDateDir reports whether name looks like a folder made by a date layout
+// inside of a year folder.
+func isDateDir(name string) bool {
+	return monthDirPattern.MatchString(name) ||
+		weekDirPattern.MatchString(name) ||
+		quarterDirPattern.MatchString(name)
+}
+
+// layoutDates returns the span of dates implied by the date folders
+// between dir and path.
+func layoutDates(dir, path string) (start, end time.Time) {
+	rel, err := filepath.Rel(dir, filepath.Dir(path))
+	if err != nil {
+		return time.Time{}, time.Time{}
+	}
+	segments := strings.Split(filepath.ToSlash(rel), "/")
+	m := yearDirPattern.FindStringSubmatch(segments[0])
+	if m == nil {
+		return time.Time{}, time.Time{}
+	}
+	year, _ := strconv.Atoi(m[1])
+	month := 0
+	start = time.Date(year, 1, 1, 0, 0, 0, 0, time.Local)
+	end = start.AddDate(1, 0, 0)
+	if m[2] != "" {
+		month, _ = strconv.Atoi(m[2])
+		start = time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Local)
+		end = start.AddDate(0, 1, 0)
+	}
+	for _, seg := range segments[1:] {
+		if m = monthDirPattern.FindStringSubmatch(seg); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			if month == 0 {
+				month = n
+				start = time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Local)
+				end = start.AddDate(0, 1, 0)
+				continue
+			}
+			start = time.Date(year, time.Month(month), n, 0, 0, 0, 0, time.Local)
+			return start, start.AddDate(0, 0, 1)
+		}
+		if m = weekDirPattern.FindStringSubmatch(seg); m != nil {
+			week, _ := strconv.Atoi(m[1])
+			start = isoWeekStart(year, week)
+			return start, start.AddDate(0, 0, 7)
+		}
+		if m = quarterDirPattern.FindStringSubmatch(seg); m != nil {
+			q, _ := strconv.Atoi(m[1])
+			start = time.Date(year, time.Month(3*q-2), 1, 0, 0, 0, 0, time.Local)
+			return start, start.AddDate(0, 3, 0)
+		}
+		break
+	}
+	return start, end
+}
+
+// isoWeekStart returns the Monday that starts ISO week of year.
+func isoWeekStart(year, week int) time.Time {
+	// Week 1 is the week with January 4th in it
+	jan4 := time.Date(year, 1, 4, 0, 0, 0, 0, time.Local)
+	monday := jan4.AddDate(0, 0, -(int(jan4.Weekday())+6)%7)
+	return monday.AddDate(0, 0, 7*(week-1))
+}
+
+// monthNames are the names of months in some languages, by language code.
+var monthNames = map[string][12]string{
+	"de": {"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+	"es": {"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+	"fr": {"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+	"it": {"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno", "luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre"},
+	"nl": {"januari", "februari", "maart", "april", "mei", "juni", "juli", "augustus", "september", "oktober", "november", "december"},
+	"pt": {"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+	"sv": {"januari", "februari", "mars", "april", "maj", "juni", "juli", "augusti", "september", "oktober", "november", "december"},
+}
+
+// monthName returns the name of month in the language of the user's locale,
+// as set by LC_ALL, LC_TIME, or LANG, or in English.
+func monthName(month time.Month) string {
+	for _, key := range []string{"LC_ALL", "LC_TIME", "LANG"} {
+		locale := os.Getenv(key)
+		if locale == "" {
+			continue
+		}
+		lang, _, _ := strings.Cut(locale, "_")
+		if names, ok := monthNames[strings.ToLower(lang)]; ok {
+			return names[month-1]
+		}
+		break
+	}
+	return month.String()
+}
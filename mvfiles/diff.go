@@ -0,0 +1,108 @@
+package mvfiles
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"text/tabwriter"
+)
+
+// Kinds of planChange
+const (
+	changeAdded   = "added"   // planned now but not before
+	changeRemoved = "removed" // planned before but not now
+	changeMoved   = "changed" // planned for a different destination
+)
+
+// planChange is a difference between a saved plan and a new one.
+type planChange struct {
+	Change string `json:"change"`
+	Old    string `json:"source"`
+	// Was and New are the destinations in the saved and new plans,
+	// with "duplicate of " in front for duplicates.
+	Was string `json:"was,omitempty"`
+	New string `json:"destination,omitempty"`
+}
+
+// diffPlans returns the changes from the moves in saved to those in moves,
+// sorted by source.
+func diffPlans(saved, moves []Move) []planChange {
+	dest := func(m Move) string {
+		if m.Duplicate {
+			return "duplicate of " + m.New
+		}
+		return m.New
+	}
+	was := make(map[string]string, len(saved))
+	for _, m := range saved {
+		was[m.Old] = dest(m)
+	}
+	var changes []planChange
+	for _, m := range moves {
+		prev, ok := was[m.Old]
+		switch {
+		case !ok:
+			changes = append(changes, planChange{changeAdded, m.Old, "", dest(m)})
+		case prev != dest(m):
+			changes = append(changes, planChange{changeMoved, m.Old, prev, dest(m)})
+		}
+		delete(was, m.Old)
+	}
+	for old, prev := range was {
+		changes = append(changes, planChange{changeRemoved, old, prev, ""})
+	}
+	slices.SortFunc(changes, func(a, b planChange) int {
+		return cmp.Compare(a.Old, b.Old)
+	})
+	return changes
+}
+
+func writeDiff(w io.Writer, format string, changes []planChange) error {
+	switch format {
+	case formatJSON:
+		if changes == nil {
+			changes = []planChange{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(changes)
+	case formatNDJSON:
+		enc := json.NewEncoder(w)
+		for _, c := range changes {
+			if err := enc.Encode(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	case formatTable:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "CHANGE\tSOURCE\tWAS\tDESTINATION")
+		for _, c := range changes {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", c.Change, c.Old, c.Was, c.New)
+		}
+		return tw.Flush()
+	}
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"change", "old", "was", "new"})
+	for _, c := range changes {
+		_ = cw.Write([]string{c.Change, c.Old, c.Was, c.New})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// printDiff compares moves to the plan saved in app.diff
+// and prints what changed.
+func (app *appEnv) printDiff(moves []Move) error {
+	saved, err := readPlan(app.diff)
+	if err != nil {
+		return err
+	}
+	changes := diffPlans(saved, moves)
+	app.Info("compared plans", "saved", len(saved), "planned", len(moves), "changes", len(changes))
+	return writeDiff(os.Stdout, app.format, changes)
+}
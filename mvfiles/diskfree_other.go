@@ -0,0 +1,15 @@
+//go:build !darwin && !linux && !windows
+
+package mvfiles
+
+import "errors"
+
+// volumeID returns the same identifier for every path,
+// since free space can't be checked.
+func volumeID(path string) (string, error) {
+	return "", nil
+}
+
+func diskFree(path string) (int64, error) {
+	return 0, errors.ErrUnsupported
+}
@@ -0,0 +1,31 @@
+//go:build darwin || linux
+
+package mvfiles
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// volumeID returns an identifier for the volume path is on.
+func volumeID(path string) (string, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return "", err
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", fmt.Errorf("no device for %q", path)
+	}
+	return fmt.Sprint(st.Dev), nil
+}
+
+// diskFree returns the bytes available to the user on the volume path is on.
+func diskFree(path string) (int64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, &os.PathError{Op: "statfs", Path: path, Err: err}
+	}
+	return int64(st.Bavail) * int64(st.Bsize), nil
+}
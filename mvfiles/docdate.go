@@ -0,0 +1,136 @@
+package mvfiles
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// pdfSearchSize is how much of each end of a PDF to search for its creation date.
+// The document info is usually near the end and XMP metadata near the start.
+const pdfSearchSize = 1 << 20
+
+var errNoDocDate = errors.New("no document creation date")
+
+var (
+	// pdfCreationDate matches the date in a PDF's document info,
+	// like /CreationDate (D:20240517103000+02'00')
+	pdfCreationDate = regexp.MustCompile(`/CreationDate\s*\(D:(\d{4})(\d{2})?(\d{2})?(\d{2})?(\d{2})?(\d{2})?([Zz]|[+-]\d{2}'?\d{2}'?)?`)
+	// xmpCreateDate matches the date in XMP metadata, either as an element or an attribute
+	xmpCreateDate = regexp.MustCompile(`xmp:CreateDate(?:>|=")([^<"]+)`)
+	// coreCreated matches the date in the core properties of an Office document
+	coreCreated = regexp.MustCompile(`<dcterms:created[^>]*>([^<]+)<`)
+)
+
+// getDocDate returns when the PDF or Office document at path in src was created
+// according to its embedded metadata. Office Open XML formats
+// keep it in docProps/core.xml.
+func getDocDate(src FileSource, path string) (time.Time, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	switch {
+	case ext == ".pdf":
+		return getPDFDate(src, path)
+	case ext == ".docx" || ext == ".xlsx" || ext == ".pptx":
+		return getOfficeDate(src, path)
+	}
+	return time.Time{}, fmt.Errorf("%w in %q: unsupported format", errNoDocDate, path)
+}
+
+func getPDFDate(src FileSource, path string) (time.Time, error) {
+	f, info, err := openReaderAt(src, path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer f.Close()
+	head := make([]byte, min(info.Size(), pdfSearchSize))
+	if _, err = io.ReadFull(f, head); err != nil {
+		return time.Time{}, err
+	}
+	tail := head
+	if info.Size() > pdfSearchSize {
+		tail = make([]byte, pdfSearchSize)
+		if _, err = f.ReadAt(tail, info.Size()-pdfSearchSize); err != nil {
+			return time.Time{}, err
+		}
+	}
+	for _, b := range [][]byte{tail, head} {
+		// The last info in the file is from the latest update
+		if all := pdfCreationDate.FindAllSubmatch(b, -1); len(all) > 0 {
+			if t, err := parsePDFDate(all[len(all)-1]); err == nil {
+				return t, nil
+			}
+		}
+		if m := xmpCreateDate.FindSubmatch(b); m != nil {
+			if t, err := parseISODate(string(m[1])); err == nil {
+				return t, nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w in %q", errNoDocDate, path)
+}
+
+// parsePDFDate parses the submatches of pdfCreationDate.
+func parsePDFDate(m [][]byte) (time.Time, error) {
+	value, layout := string(m[1]), "2006"
+	for i, part := range []string{"01", "02", "15", "04", "05"} {
+		if len(m[i+2]) == 0 {
+			break
+		}
+		value += string(m[i+2])
+		layout += part
+	}
+	loc := time.Local
+	if tz := strings.ReplaceAll(string(m[7]), "'", ""); tz != "" {
+		loc = time.UTC
+		if tz != "Z" && tz != "z" {
+			offset, err := time.Parse("-0700", tz)
+			if err != nil {
+				return time.Time{}, err
+			}
+			loc = offset.Location()
+		}
+	}
+	return time.ParseInLocation(layout, value, loc)
+}
+
+// parseISODate parses dates like 2024-05-17T10:30:00Z or 2024-05-17.
+func parseISODate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02T15:04", time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("bad date %q", s)
+}
+
+func getOfficeDate(src FileSource, path string) (time.Time, error) {
+	zf, info, err := openReaderAt(src, path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer zf.Close()
+	zr, err := zip.NewReader(zf, info.Size())
+	if err != nil {
+		return time.Time{}, err
+	}
+	f, err := zr.Open("docProps/core.xml")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w in %q: %v", errNoDocDate, path, err)
+	}
+	defer f.Close()
+	b, err := io.ReadAll(io.LimitReader(f, 1<<20))
+	if err != nil {
+		return time.Time{}, err
+	}
+	m := coreCreated.FindSubmatch(b)
+	if m == nil {
+		return time.Time{}, fmt.Errorf("%w in %q", errNoDocDate, path)
+	}
+	return parseISODate(string(m[1]))
+}
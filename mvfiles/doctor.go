@@ -0,0 +1,155 @@
+package mvfiles
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// Problems found by Doctor
+const (
+	ProblemMisfiled  = "misfiled"
+	ProblemUnknown   = "unknown folder"
+	ProblemEmpty     = "empty folder"
+	ProblemCollision = "collision"
+)
+
+// Problem is something wrong with an organized tree.
+type Problem struct {
+	Problem string `json:"problem"`
+	Path    string `json:"path"`
+	// Fix is where the item at Path should be moved, if anywhere.
+	Fix string `json:"fix,omitempty"`
+}
+
+// Doctor audits the year folders in dir. It reports items that are misfiled
+// according to their kinds and dates under opts, folders in the year folders
+// that are not part of the layout, empty folders, and items in the same
+// folder whose names only differ by case or Unicode normalization.
+func Doctor(dir string, opts Options) ([]Problem, error) {
+	r, err := opts.runner(dir)
+	if err != nil {
+		return nil, err
+	}
+	paths, dirpaths, err := r.scanLayout()
+	if err != nil {
+		return nil, err
+	}
+	empty, err := Prune(dir, true)
+	if err != nil {
+		return nil, err
+	}
+	var problems []Problem
+	for _, path := range empty {
+		problems = append(problems, Problem{Problem: ProblemEmpty, Path: path})
+	}
+	// Empty folders are removed rather than moved
+	skip := make(map[string]bool)
+	for _, path := range empty {
+		skip[path] = true
+	}
+	depth := len(strings.Split(r.Template, "/"))
+	for _, path := range dirpaths {
+		// Directories have no kind, so they are at least one level up
+		rel, _ := filepath.Rel(dir, path)
+		if !skip[path] && strings.Count(rel, string(filepath.Separator)) < depth-1 {
+			skip[path] = true
+			problems = append(problems, Problem{Problem: ProblemUnknown, Path: path})
+		}
+	}
+	moves, err := r.reorganize(false)
+	if err != nil {
+		return nil, err
+	}
+	var misfiled []Problem
+	moved := make(map[string]bool)
+	for _, m := range moves {
+		if !skip[m.Old] {
+			moved[m.Old] = true
+			misfiled = append(misfiled, Problem{Problem: ProblemMisfiled, Path: m.Old, Fix: m.New})
+		}
+	}
+	// Look for names that only differ by case or Unicode normalization
+	// where items are now, and then where misfiled items will be
+	claimed := make(pathSet)
+	owners := make(map[string]bool)
+	claim := func(path string) (string, error) {
+		if owners[foldName(path)] {
+			var err error
+			if path, err = freeName(r.Source, path, claimed); err != nil {
+				return "", err
+			}
+		}
+		claimed.add(path)
+		owners[foldName(path)] = true
+		return path, nil
+	}
+	for _, path := range slices.Concat(paths, dirpaths) {
+		if moved[path] || skip[path] {
+			continue
+		}
+		fix, err := claim(path)
+		if err != nil {
+			return nil, err
+		}
+		if fix != path {
+			problems = append(problems, Problem{Problem: ProblemCollision, Path: path, Fix: fix})
+		}
+	}
+	for i := range misfiled {
+		if misfiled[i].Fix, err = claim(misfiled[i].Fix); err != nil {
+			return nil, err
+		}
+	}
+	problems = append(problems, misfiled...)
+	return problems, nil
+}
+
+func writeProblems(w io.Writer, problems []Problem) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "PROBLEM\tPATH\tFIX")
+	for _, p := range problems {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", p.Problem, p.Path, p.Fix)
+	}
+	return tw.Flush()
+}
+
+// Doctor reports the problems in app.dir and fixes them if app.fix is set.
+// Misfiled items are moved, collisions are renamed, and empty folders
+// are removed. Unknown folders are left for the user to sort out.
+func (app *appEnv) Doctor(ctx context.Context) (err error) {
+	problems, err := Doctor(app.dir, app.opts)
+	if err != nil {
+		return err
+	}
+	if len(problems) == 0 {
+		app.Info("no problems found", "dir", app.dir)
+		return nil
+	}
+	if !app.fix {
+		return writeProblems(os.Stdout, problems)
+	}
+	unlock, err := lockDir(ctx, app.dir, app.wait)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, unlock())
+	}()
+	var moves []Move
+	for _, p := range problems {
+		if p.Fix != "" {
+			moves = append(moves, Move{Old: p.Path, New: p.Fix})
+		}
+	}
+	if err = app.execute(ctx, moves); err != nil {
+		return err
+	}
+	return app.Prune(ctx)
+}
@@ -0,0 +1,31 @@
+package mvfiles
+
+import (
+	"bufio"
+	"net/mail"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// getEmailDate returns the Date header of the .eml or .emlx message
+// at path in src.
+// Apple Mail's .emlx files start with a line holding the message's length.
+func getEmailDate(src FileSource, path string) (time.Time, error) {
+	f, err := src.Open(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer f.Close()
+	br := bufio.NewReader(f)
+	if strings.EqualFold(filepath.Ext(path), ".emlx") {
+		if _, err = br.ReadString('\n'); err != nil {
+			return time.Time{}, err
+		}
+	}
+	msg, err := mail.ReadMessage(br)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return msg.Header.Date()
+}
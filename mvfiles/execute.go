@@ -0,0 +1,233 @@
+package mvfiles
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// FailedMove is a move that could not be made.
+type FailedMove struct {
+	Move
+	Err error
+}
+
+func (f *FailedMove) Error() string {
+	return f.Err.Error()
+}
+
+func (f *FailedMove) Unwrap() error {
+	return f.Err
+}
+
+// FailedMovesError is returned by Execute when opts.KeepGoing is set
+// and some of the moves failed.
+type FailedMovesError struct {
+	Failures []FailedMove
+	Total    int
+}
+
+func (e *FailedMovesError) Error() string {
+	return fmt.Sprintf("%d of %d moves failed", len(e.Failures), e.Total)
+}
+
+// Execute carries out moves, recording them in opts.Journal.
+// Before moving anything, it checks that the destinations are writable
+// and have room for files copied from other volumes.
+// If opts.Copy is set, the files are copied instead.
+// If opts.Throttle is set, Execute pauses that long between moves.
+// If ctx is canceled, Execute finishes the move in progress
+// and returns an error reporting how many moves were completed.
+// If a move fails, Execute stops and returns a *FailedMove,
+// unless opts.KeepGoing is set, in which case it continues
+// and returns a *FailedMovesError listing the failures.
+func Execute(ctx context.Context, moves []Move, opts Options) (err error) {
+	r, err := opts.runner("")
+	if err != nil {
+		return err
+	}
+	return r.executeAll(ctx, moves, "")
+}
+
+// executeAll is Execute. If run is set, the moves are journaled
+// as part of that run instead of a new one.
+//
+// Before moving anything, executeAll records the settings of the run
+// and the intent to make each move, and once it is done, it records
+// the end of the run, so that Resume can finish or roll back a run
+// that was interrupted.
+func (r *runner) executeAll(ctx context.Context, moves []Move, run string) (err error) {
+	j, err := openJournal(r.Journal)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, j.Close())
+	}()
+	if run == "" {
+		run = newRunID()
+	}
+	if j != nil {
+		j.run = run
+	}
+	r.run = run
+	if err = r.preflight(moves); err != nil {
+		return err
+	}
+	r.checkSync(moves)
+	if err = j.start(r); err != nil {
+		return err
+	}
+	for _, m := range moves {
+		if m.Duplicate {
+			continue
+		}
+		if err = j.record(actionIntent, m.Old, m.New); err != nil {
+			return err
+		}
+	}
+	var failures []FailedMove
+	for i, m := range moves {
+		if err = ctx.Err(); err != nil {
+			return fmt.Errorf("stopped after %d of %d moves: %w", i, len(moves), err)
+		}
+		if i > 0 && r.Throttle > 0 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("stopped after %d of %d moves: %w", i, len(moves), ctx.Err())
+			case <-time.After(r.Throttle):
+			}
+		}
+		if err = r.execute(j, m); err != nil {
+			if !r.KeepGoing {
+				return &FailedMove{m, err}
+			}
+			r.Logger.Warn("failed to move", "old", m.Old, "new", m.New, "error", err)
+			failures = append(failures, FailedMove{m, err})
+		}
+		r.Progress("moving", i+1, len(moves), m.Old)
+	}
+	if err = j.record(actionEnd, "", ""); err != nil {
+		return err
+	}
+	if len(failures) > 0 {
+		return &FailedMovesError{failures, len(moves)}
+	}
+	return nil
+}
+
+func (r *runner) execute(j *journal, m Move) (err error) {
+	if m.Duplicate {
+		if !r.DedupeTrash {
+			r.Logger.Info("skipping duplicate", "old", m.Old, "new", m.New)
+			r.Skipped(m, "duplicate")
+			return nil
+		}
+		trashed, err := trashFile(m.Old)
+		if err != nil {
+			return err
+		}
+		r.Logger.Info(actionTrash, "old", m.Old, "new", trashed)
+		return j.record(actionTrash, m.Old, trashed)
+	}
+	if isPhotosImport(m.New) {
+		if err = r.importPhoto(j, m); err == nil {
+			r.Moved(m)
+		}
+		return err
+	}
+	// Check again in case something has changed since planning
+	if m.New, err = resolveConflict(r.Source, r.OnConflict, m.New, nil); err != nil {
+		return err
+	}
+	if m.New == "" {
+		r.Logger.Info("skipping", "old", m.Old, "reason", "destination exists")
+		r.Skipped(m, "destination exists")
+		return nil
+	}
+	if r.SkipOpen {
+		open, err := isOpen(m.Old)
+		if err != nil {
+			return err
+		}
+		if open {
+			r.Logger.Warn("skipping", "old", m.Old, "reason", "open in another process")
+			r.Skipped(m, "open in another process")
+			return nil
+		}
+	}
+	if r.SyncSafe && r.syncClientOf(m.Old) != "" && !r.waitSynced(m.Old) {
+		r.Logger.Warn("skipping", "old", m.Old, "reason", "still syncing")
+		r.Skipped(m, "still syncing")
+		return nil
+	}
+	if r.OnConflict == ConflictTrash {
+		if _, err = r.Source.Lstat(m.New); err == nil {
+			trashed, err := trashFile(m.New)
+			if err != nil {
+				return err
+			}
+			r.Logger.Info(actionTrash, "old", m.New, "new", trashed)
+			if err = j.record(actionTrash, m.New, trashed); err != nil {
+				return err
+			}
+		}
+	}
+	if err = r.mkdirAll(j, filepath.Dir(m.New)); err != nil {
+		return err
+	}
+	if r.Localize && m.Kind != "" {
+		if err = r.localizeKindDir(m.New, m.Kind, m.Size); err != nil {
+			return err
+		}
+	}
+	if err = r.runFileHooks(hookBefore, m); err != nil {
+		return err
+	}
+	if m.Checksum == "" && r.Checksum == ChecksumSHA256 && !isPlaceholder(m.Old) {
+		// Plans made without -checksum don't have them
+		if info, err := r.Source.Lstat(m.Old); err == nil && info.Mode().IsRegular() {
+			if m.Checksum, err = fileChecksum(r.Source, m.Old); err != nil {
+				return err
+			}
+		}
+	}
+	action, transfer := actionMove, r.Mover.Move
+	if r.Copy {
+		action, transfer = actionCopy, r.Mover.Copy
+	}
+	if err = transfer(m.Old, m.New); err != nil {
+		return err
+	}
+	r.Logger.Info(action, "old", m.Old, "new", m.New)
+	if err = j.recordSum(action, m.Old, m.New, m.Checksum); err != nil {
+		return err
+	}
+	r.Moved(m)
+	if err := setOrigin(m.New, m.Old, r.run); err != nil {
+		r.Logger.Debug("could not record origin", "path", m.New, "error", err)
+	}
+	if r.LeaveSymlink > 0 && !r.Copy {
+		if err := r.leaveSymlink(j, m.Old, m.New); err != nil {
+			r.Logger.Warn("could not leave link", "path", m.Old, "error", err)
+		}
+	}
+	if r.TagKinds && m.Kind != "" {
+		// The move is done, so a missing tag is not worth failing over
+		if err := tagFile(m.New, m.Kind, tagColors[r.TagColor]); err != nil {
+			r.Logger.Warn("could not tag", "path", m.New, "error", err)
+		}
+	}
+	if r.ClearQuarantine && slices.Contains(quarantineKinds, m.Kind) {
+		if err := clearQuarantine(m.New); err != nil {
+			r.Logger.Warn("could not clear quarantine", "path", m.New, "error", err)
+		}
+	}
+	if err := r.runFileHooks(hookAfter, m); err != nil {
+		r.Logger.Warn("hook failed", "path", m.New, "error", err)
+	}
+	return nil
+}
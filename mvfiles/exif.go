@@ -0,0 +1,134 @@
+package mvfiles
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// EXIF tags
+const (
+	tagDateTime           = 0x0132
+	tagExifIFD            = 0x8769
+	tagDateTimeOriginal   = 0x9003
+	tagOffsetTimeOriginal = 0x9011
+)
+
+// exifSearchSize is how much of a file to search for EXIF data.
+// JPEG and HEIC files from cameras and phones keep it near the start.
+const exifSearchSize = 256 << 10
+
+var errNoEXIF = errors.New("no EXIF date")
+
+// getEXIFDate returns when the photo at path in src was taken
+// according to its EXIF metadata.
+// It finds the metadata by looking for the header used by JPEG and HEIC files
+// near the start of the file, and also handles plain TIFF files.
+func getEXIFDate(src FileSource, path string) (time.Time, error) {
+	f, err := src.Open(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer f.Close()
+	b, err := io.ReadAll(io.LimitReader(f, exifSearchSize))
+	if err != nil {
+		return time.Time{}, err
+	}
+	tiff := b
+	if !isTIFFHeader(b) {
+		i := bytes.Index(b, []byte("Exif\x00\x00"))
+		if i == -1 {
+			return time.Time{}, fmt.Errorf("%w in %q", errNoEXIF, path)
+		}
+		tiff = b[i+6:]
+	}
+	t, err := parseTIFFDate(tiff)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w in %q: %v", errNoEXIF, path, err)
+	}
+	return t, nil
+}
+
+func isTIFFHeader(b []byte) bool {
+	return bytes.HasPrefix(b, []byte("II*\x00")) || bytes.HasPrefix(b, []byte("MM\x00*"))
+}
+
+type tiffReader struct {
+	b     []byte
+	order binary.ByteOrder
+}
+
+func parseTIFFDate(b []byte) (time.Time, error) {
+	if !isTIFFHeader(b) || len(b) < 8 {
+		return time.Time{}, errors.New("bad TIFF header")
+	}
+	tr := tiffReader{b, binary.ByteOrder(binary.LittleEndian)}
+	if b[0] == 'M' {
+		tr.order = binary.BigEndian
+	}
+	ifd0, err := tr.readIFD(tr.order.Uint32(b[4:]))
+	if err != nil {
+		return time.Time{}, err
+	}
+	var date, offset string
+	if off, ok := ifd0[tagExifIFD]; ok {
+		exif, err := tr.readIFD(tr.order.Uint32(off[8:]))
+		if err != nil {
+			return time.Time{}, err
+		}
+		date = tr.ascii(exif[tagDateTimeOriginal])
+		offset = tr.ascii(exif[tagOffsetTimeOriginal])
+	}
+	if date == "" {
+		date = tr.ascii(ifd0[tagDateTime])
+	}
+	if date == "" {
+		return time.Time{}, errors.New("no date tag")
+	}
+	if offset != "" {
+		return time.Parse("2006:01:02 15:04:05-07:00", date+offset)
+	}
+	return time.ParseInLocation("2006:01:02 15:04:05", date, time.Local)
+}
+
+// readIFD returns the 12 byte entries of the IFD at offset keyed by tag.
+func (tr tiffReader) readIFD(offset uint32) (map[uint16][]byte, error) {
+	if int(offset)+2 > len(tr.b) {
+		return nil, errors.New("IFD out of range")
+	}
+	n := int(tr.order.Uint16(tr.b[offset:]))
+	start := int(offset) + 2
+	if start+n*12 > len(tr.b) {
+		return nil, errors.New("IFD truncated")
+	}
+	entries := make(map[uint16][]byte, n)
+	for i := range n {
+		entry := tr.b[start+i*12 : start+(i+1)*12]
+		entries[tr.order.Uint16(entry)] = entry
+	}
+	return entries, nil
+}
+
+// ascii returns the value of an ASCII entry, or "" if it is missing or invalid.
+func (tr tiffReader) ascii(entry []byte) string {
+	const typeASCII = 2
+	if entry == nil || tr.order.Uint16(entry[2:]) != typeASCII {
+		return ""
+	}
+	count := int(tr.order.Uint32(entry[4:]))
+	value := entry[8:12]
+	if count > 4 {
+		offset := int(tr.order.Uint32(entry[8:]))
+		if offset+count > len(tr.b) {
+			return ""
+		}
+		value = tr.b[offset : offset+count]
+	} else {
+		value = value[:count]
+	}
+	return strings.TrimRight(string(value), "\x00 ")
+}
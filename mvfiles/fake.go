@@ -0,0 +1,39 @@
+package mvfiles
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNoDate is returned by FakeMetadata for a file with no date from a source,
+// like a file whose date added attribute is missing.
+var ErrNoDate = errors.New("no date")
+
+// FakeMetadata is a MetadataProvider with made up dates and errors,
+// so that tests can see how plans handle them without real files.
+type FakeMetadata struct {
+	// Dates are the dates of files by path and then date source.
+	Dates map[string]map[string]time.Time
+	// Errors are the errors looking up the dates of files by path and then
+	// date source, like fs.ErrPermission. The source "" stands for every source.
+	Errors map[string]map[string]error
+}
+
+func (fm *FakeMetadata) Date(source, path string) (time.Time, error) {
+	if err := fm.Errors[path][source]; err != nil {
+		return time.Time{}, fmt.Errorf("%s date of %q: %w", source, path, err)
+	}
+	if err := fm.Errors[path][""]; err != nil {
+		return time.Time{}, fmt.Errorf("%s date of %q: %w", source, path, err)
+	}
+	if t, ok := fm.Dates[path][source]; ok {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%s date of %q: %w", source, path, ErrNoDate)
+}
+
+// FixedClock returns a clock for Options.Now that always returns t.
+func FixedClock(t time.Time) func() time.Time {
+	return func() time.Time { return t }
+}
@@ -0,0 +1,164 @@
+package mvfiles
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// When a file hook runs.
+const (
+	hookBefore = "before"
+	hookAfter  = "after"
+)
+
+// hookConfig is the format of a hook in the kinds file:
+//
+//	[[hooks]]
+//	kinds = ["doc"]
+//	exts = ["pdf"]
+//	before = ["ocrmypdf", "--skip-text", "{{.Source}}", "{{.Source}}"]
+//	after = ["xattr", "-d", "com.apple.quarantine", "{{.Destination}}"]
+//
+// Each argument is a template using .Source, .Destination, .Kind, and .Date.
+// Commands are run directly rather than by a shell.
+// A hook without kinds or exts runs for every file.
+type hookConfig struct {
+	Kinds  []string `toml:"kinds"`
+	Exts   []string `toml:"exts"`
+	Before []string `toml:"before"`
+	After  []string `toml:"after"`
+}
+
+// fileHook is a parsed hookConfig.
+type fileHook struct {
+	kinds  []string
+	exts   []string
+	before []*template.Template
+	after  []*template.Template
+}
+
+// hookData holds the variables available to file hooks.
+type hookData struct {
+	Source      string
+	Destination string
+	Kind        string
+	Date        time.Time
+}
+
+func parseFileHook(conf hookConfig) (fileHook, error) {
+	h := fileHook{kinds: conf.Kinds}
+	for _, ext := range conf.Exts {
+		h.exts = append(h.exts, strings.ToLower(strings.TrimPrefix(ext, ".")))
+	}
+	for _, args := range []struct {
+		dst  *[]*template.Template
+		args []string
+	}{{&h.before, conf.Before}, {&h.after, conf.After}} {
+		for _, arg := range args.args {
+			t, err := parseTemplate(arg)
+			if err != nil {
+				return h, err
+			}
+			*args.dst = append(*args.dst, t)
+		}
+	}
+	return h, nil
+}
+
+func (h fileHook) matches(m Move) bool {
+	if m.Kind == "" {
+		return false
+	}
+	if len(h.kinds) > 0 && !slices.Contains(h.kinds, m.Kind) {
+		return false
+	}
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(m.Old), "."))
+	return len(h.exts) == 0 || slices.Contains(h.exts, ext)
+}
+
+// hookCommands returns the commands of the hooks in km to run
+// when (before or after) making m.
+func (km *Kinds) hookCommands(when string, m Move) ([][]string, error) {
+	if km == nil {
+		return nil, nil
+	}
+	data := hookData{Source: m.Old, Destination: m.New, Kind: m.Kind, Date: m.Date}
+	var cmds [][]string
+	for _, h := range km.hooks {
+		ts := h.before
+		if when == hookAfter {
+			ts = h.after
+		}
+		if len(ts) == 0 || !h.matches(m) {
+			continue
+		}
+		var cmd []string
+		for _, t := range ts {
+			var sb strings.Builder
+			if err := t.Execute(&sb, data); err != nil {
+				return nil, fmt.Errorf("%s hook: %w", when, err)
+			}
+			cmd = append(cmd, sb.String())
+		}
+		cmds = append(cmds, cmd)
+	}
+	return cmds, nil
+}
+
+// runFileHooks runs the hooks in r.Kinds for m.
+func (r *runner) runFileHooks(when string, m Move) error {
+	cmds, err := r.Kinds.hookCommands(when, m)
+	if err != nil {
+		return err
+	}
+	for _, args := range cmds {
+		r.Logger.Debug("running hook", "when", when, "command", args)
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s hook %s: %w", when, args[0], err)
+		}
+	}
+	return nil
+}
+
+// printFileHooks prints the hooks that would run for moves to stderr.
+func (app *appEnv) printFileHooks(moves []Move) error {
+	for _, m := range moves {
+		if m.Duplicate {
+			continue
+		}
+		for _, when := range []string{hookBefore, hookAfter} {
+			cmds, err := app.opts.Kinds.hookCommands(when, m)
+			if err != nil {
+				return err
+			}
+			for _, args := range cmds {
+				quoted := make([]string, len(args))
+				for i, arg := range args {
+					quoted[i] = shellQuote(arg)
+				}
+				fmt.Fprintf(os.Stderr, "would run %s %s: %s\n", when, m.Old, strings.Join(quoted, " "))
+			}
+		}
+	}
+	return nil
+}
+
+// shellQuote quotes s for display as a POSIX shell word if needed.
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsFunc(s, func(r rune) bool {
+		return !(r == '-' || r == '_' || r == '.' || r == '/' || r == ',' || r == ':' || r == '=' ||
+			'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9')
+	}) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,54 @@
+package mvfiles
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// readFileList reads the paths listed in name, or standard input if name is "-",
+// one per line, or separated by NUL characters if nul is set.
+func readFileList(name string, nul bool) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	if nul {
+		s.Split(scanNul)
+	}
+	paths := []string{}
+	for s.Scan() {
+		path := s.Text()
+		if !nul {
+			path = strings.TrimSuffix(path, "\r")
+		}
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("reading file list: %w", err)
+	}
+	return paths, nil
+}
+
+// scanNul is a bufio.SplitFunc for NUL separated items.
+func scanNul(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
@@ -0,0 +1,48 @@
+package mvfiles
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// filter returns why m should be left out of the plan,
+// or the empty string if it should be included.
+func (r *runner) filter(m Move) string {
+	for _, ext := range downloadControlExts {
+		if _, err := r.Source.Lstat(m.Old + ext); err == nil {
+			return "download in progress"
+		}
+	}
+	if r.Settle > 0 {
+		if info, err := r.Source.Lstat(m.Old); err == nil && r.Now().Sub(info.ModTime()) < r.Settle {
+			return fmt.Sprintf("modified in the last %v", r.Settle)
+		}
+	}
+	if r.AirDrop && getApp(r.Source, m.Old) != "AirDrop" {
+		return "not received with AirDrop"
+	}
+	if r.SkipQuarantined && isUnopenedQuarantine(r.Source, m.Old) {
+		return "quarantined and not opened yet"
+	}
+	if len(r.OnlyKinds) > 0 && !slices.Contains(r.OnlyKinds, m.Kind) {
+		return fmt.Sprintf("kind %q not selected", m.Kind)
+	}
+	if slices.Contains(r.SkipKinds, m.Kind) {
+		return fmt.Sprintf("kind %q skipped", m.Kind)
+	}
+	age := r.Now().Sub(m.Date)
+	if r.OlderThan > 0 && age < r.OlderThan {
+		return fmt.Sprintf("newer than %v", r.OlderThan)
+	}
+	if r.NewerThan > 0 && age > r.NewerThan {
+		return fmt.Sprintf("older than %v", r.NewerThan)
+	}
+	if !r.Since.IsZero() && m.Date.Before(r.Since) {
+		return fmt.Sprintf("before %s", r.Since.Format(time.DateOnly))
+	}
+	if !r.Until.IsZero() && !m.Date.Before(r.Until) {
+		return fmt.Sprintf("after %s", r.Until.AddDate(0, 0, -1).Format(time.DateOnly))
+	}
+	return ""
+}
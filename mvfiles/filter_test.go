@@ -0,0 +1,63 @@
+package mvfiles
+
+import (
+	"maps"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPlanDateFilters(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	files := map[string]string{
+		"/in/old.pdf":    "",
+		"/in/mid.jpg":    "",
+		"/in/recent.pdf": "",
+	}
+	meta := added(map[string]time.Time{
+		"/in/old.pdf":    time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC),
+		"/in/mid.jpg":    time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC),
+		"/in/recent.pdf": time.Date(2024, 5, 30, 0, 0, 0, 0, time.UTC),
+	})
+	month := 30 * 24 * time.Hour
+	for _, tc := range []struct {
+		name string
+		set  func(*Options)
+		want []string
+	}{
+		{"all", func(*Options) {}, []string{"old.pdf", "mid.jpg", "recent.pdf"}},
+		{"older than", func(o *Options) { o.OlderThan = month }, []string{"old.pdf", "mid.jpg"}},
+		{"newer than", func(o *Options) { o.NewerThan = month }, []string{"recent.pdf"}},
+		{"older and newer than", func(o *Options) { o.OlderThan, o.NewerThan = month, 6*month }, []string{"mid.jpg"}},
+		{"since", func(o *Options) { o.Since = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) }, []string{"mid.jpg", "recent.pdf"}},
+		{"until", func(o *Options) { o.Until = time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC) }, []string{"old.pdf"}},
+		{"since and until", func(o *Options) {
+			o.Since = time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+			o.Until = time.Date(2024, 5, 30, 0, 0, 0, 0, time.UTC)
+		}, []string{"mid.jpg"}},
+		{"only kinds", func(o *Options) { o.OnlyKinds = []string{"doc"} }, []string{"old.pdf", "recent.pdf"}},
+		{"skip kinds", func(o *Options) { o.SkipKinds = []string{"doc"} }, []string{"mid.jpg"}},
+		{"kinds and dates", func(o *Options) { o.OnlyKinds = []string{"doc"}; o.OlderThan = month }, []string{"old.pdf"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newTestFS(t, files)
+			opts := fakeOptions(m, meta, now)
+			tc.set(&opts)
+			moves, err := Plan(filepath.FromSlash("/in"), opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[string]bool)
+			for _, mv := range moves {
+				got[filepath.Base(mv.Old)] = true
+			}
+			want := make(map[string]bool)
+			for _, name := range tc.want {
+				want[name] = true
+			}
+			if !maps.Equal(got, want) {
+				t.Errorf("planned %v, want %v", got, want)
+			}
+		})
+	}
+}
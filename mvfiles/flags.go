@@ -0,0 +1,71 @@
+package mvfiles
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// choiceVar defines a string flag that only accepts one of choices.
+func choiceVar(fl *flag.FlagSet, p *string, name, value, usage string, choices ...string) {
+	*p = value
+	list := strings.Join(choices, ", ")
+	usage = fmt.Sprintf("%s: %s (default %q)", usage, list, value)
+	fl.Func(name, usage, func(s string) error {
+		if !slices.Contains(choices, s) {
+			return fmt.Errorf("must be one of %s", list)
+		}
+		*p = s
+		return nil
+	})
+}
+
+// dateVar defines a flag that takes a local date, like 2024-12-31.
+// The time is set to the start of the date plus days.
+func dateVar(fl *flag.FlagSet, p *time.Time, name, usage string, days int) {
+	fl.Func(name, usage, func(s string) error {
+		t, err := time.ParseInLocation(time.DateOnly, s, time.Local)
+		if err != nil {
+			return err
+		}
+		*p = t.AddDate(0, 0, days)
+		return nil
+	})
+}
+
+// durationVar defines a flag that takes a time.Duration
+// or a number of days, like 7d.
+func durationVar(fl *flag.FlagSet, p *time.Duration, name string, value time.Duration, usage string) {
+	*p = value
+	fl.Func(name, usage, func(s string) error {
+		if days, ok := strings.CutSuffix(s, "d"); ok {
+			n, err := strconv.Atoi(days)
+			if err != nil {
+				return err
+			}
+			*p = time.Duration(n) * 24 * time.Hour
+			return nil
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		*p = d
+		return nil
+	})
+}
+
+// listVar defines a flag that takes a comma separated list of values.
+func listVar(fl *flag.FlagSet, p *[]string, name, usage string) {
+	fl.Func(name, usage, func(s string) error {
+		for _, v := range strings.Split(s, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				*p = append(*p, v)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,84 @@
+package mvfiles
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// PlanFlatten returns the moves that undo organizing dir, pulling the contents
+// of its year folders back into dir itself, sorted by Options.Order.
+//
+// Folders inside of the year folders are treated as part of the layout
+// if they are named like a year, month, day, or kind, and are moved
+// as single items otherwise.
+func PlanFlatten(dir string, opts Options) ([]Move, error) {
+	r, err := opts.runner(dir)
+	if err != nil {
+		return nil, err
+	}
+	paths, dirpaths, err := r.scanLayout()
+	if err != nil {
+		return nil, err
+	}
+	moves, err := r.buildMoves(paths, dirpaths)
+	if err != nil {
+		return nil, err
+	}
+	for i := range moves {
+		moves[i].New = filepath.Join(dir, filepath.Base(moves[i].Old))
+	}
+	if moves, err = r.resolveConflicts(moves); err != nil {
+		return nil, err
+	}
+	return r.arrange(moves), nil
+}
+
+// scanLayout returns the items in the year folders and size route buckets of r.dir.
+func (r *runner) scanLayout() (paths, dirpaths []string, err error) {
+	entries, err := r.Source.ReadDir(r.dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && r.isArchiveDir(entry.Name()) {
+			if err = r.flattenDir(filepath.Join(r.dir, entry.Name()), &paths, &dirpaths); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return paths, dirpaths, nil
+}
+
+// flattenDir adds the items in the layout folder dir to paths and dirpaths.
+func (r *runner) flattenDir(dir string, paths, dirpaths *[]string) error {
+	entries, err := r.Source.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		switch {
+		case !entry.IsDir() || isPackage(path):
+			*paths = append(*paths, path)
+		case r.isLayoutDir(name):
+			if err = r.flattenDir(path, paths, dirpaths); err != nil {
+				return err
+			}
+		default:
+			*dirpaths = append(*dirpaths, path)
+		}
+	}
+	return nil
+}
+
+// isLayoutDir reports whether name looks like a folder made by organizing.
+func (r *runner) isLayoutDir(name string) bool {
+	if isYearDir(name) || isDateDir(name) {
+		return true
+	}
+	return r.Kinds.isKind(name)
+}
@@ -0,0 +1,43 @@
+package mvfiles
+
+import (
+	"runtime"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// foldPaths means the file system treats names that differ only by case
+// as the same, as APFS and NTFS do by default.
+var foldPaths = runtime.GOOS == "darwin" || runtime.GOOS == "windows"
+
+// normalizePaths means the file system also treats names that differ only
+// by Unicode normalization as the same, as APFS does. NTFS doesn't.
+var normalizePaths = runtime.GOOS == "darwin"
+
+// pathSet is a set of paths that compares them the way the file system does.
+// The zero value is an empty set that can't be added to.
+type pathSet map[string]bool
+
+func (s pathSet) add(path string)      { s[pathKey(path)] = true }
+func (s pathSet) has(path string) bool { return s[pathKey(path)] }
+
+// pathKey returns the form of path that names the same file as path
+// on this file system.
+func pathKey(path string) string {
+	if foldPaths {
+		// Casers keep state, so each call needs its own
+		path = cases.Fold().String(path)
+	}
+	if normalizePaths {
+		path = norm.NFD.String(path)
+	}
+	return path
+}
+
+// foldName case folds s and decomposes it,
+// so that names like "Résumé.PDF" in NFC and "résumé.pdf" in NFD compare equal
+// whatever file system they end up on.
+func foldName(s string) string {
+	return norm.NFD.String(cases.Fold().String(s))
+}
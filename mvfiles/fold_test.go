@@ -0,0 +1,58 @@
+package mvfiles
+
+import (
+	"testing"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestPathKey(t *testing.T) {
+	saved, savedNorm := foldPaths, normalizePaths
+	t.Cleanup(func() { foldPaths, normalizePaths = saved, savedNorm })
+
+	nfc, nfd := norm.NFC.String, norm.NFD.String
+	for _, tc := range []struct {
+		name string
+		a, b string
+	}{
+		{"latin", nfc("Résumé.pdf"), nfd("Résumé.pdf")},
+		{"hangul", nfc("한국어.txt"), nfd("한국어.txt")},
+		{"vietnamese", nfc("Tiếng Việt.txt"), nfd("Tiếng Việt.txt")},
+		{"greek", nfc("ώρα.txt"), nfd("ώρα.txt")},
+		{"cyrillic", nfc("йод ёж.txt"), nfd("йод ёж.txt")},
+	} {
+		if tc.a == tc.b {
+			t.Fatalf("%s: test names are the same", tc.name)
+		}
+		// APFS
+		foldPaths, normalizePaths = true, true
+		if pathKey(tc.a) != pathKey(tc.b) {
+			t.Errorf("%s: NFC and NFD names differ on a normalizing file system", tc.name)
+		}
+		// NTFS
+		foldPaths, normalizePaths = true, false
+		if pathKey(tc.a) == pathKey(tc.b) {
+			t.Errorf("%s: NFC and NFD names are the same on a file system that doesn't normalize", tc.name)
+		}
+	}
+
+	for _, tc := range []struct {
+		a, b string
+		fold bool
+	}{
+		{"Report.PDF", "report.pdf", true},
+		{"ΣΟΦΊΑ.txt", "σοφία.txt", true},
+		{"ЁЖ.txt", "ёж.txt", true},
+		{"Straße.txt", "STRASSE.txt", true},
+		{"a.txt", "b.txt", false},
+	} {
+		foldPaths, normalizePaths = true, false
+		if got := pathKey(tc.a) == pathKey(tc.b); got != tc.fold {
+			t.Errorf("case folded %q == %q: %v, want %v", tc.a, tc.b, got, tc.fold)
+		}
+		foldPaths, normalizePaths = false, false
+		if pathKey(tc.a) != tc.a {
+			t.Errorf("pathKey(%q) = %q on a case-sensitive file system", tc.a, pathKey(tc.a))
+		}
+	}
+}
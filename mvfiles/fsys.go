@@ -0,0 +1,156 @@
+package mvfiles
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// FileSource is where Plan finds the files it organizes.
+// Names are paths in the form of the operating system, as with package os.
+type FileSource interface {
+	ReadDir(name string) ([]fs.DirEntry, error)
+	Lstat(name string) (fs.FileInfo, error)
+	Open(name string) (fs.File, error)
+	Readlink(name string) (string, error)
+	// Xattr returns the value of the extended attribute attr of name,
+	// like "com.apple.metadata:_kMDItemUserTags".
+	Xattr(name, attr string) ([]byte, error)
+}
+
+// MetadataProvider looks up the dates of files.
+type MetadataProvider interface {
+	// Date returns the date of the file at path from source,
+	// one of "added", "birthtime", "filename", or "mtime".
+	Date(source, path string) (time.Time, error)
+}
+
+// Mover makes the changes to the file system that Execute carries out.
+type Mover interface {
+	MkdirAll(dir string, perm fs.FileMode) error
+	// Move moves oldpath to newpath, even across volumes.
+	Move(oldpath, newpath string) error
+	// Copy copies the file or directory tree at oldpath to newpath.
+	Copy(oldpath, newpath string) error
+}
+
+// osFiles is the FileSource for the real file system.
+type osFiles struct{}
+
+func (osFiles) ReadDir(name string) ([]fs.DirEntry, error) { return os.ReadDir(name) }
+func (osFiles) Lstat(name string) (fs.FileInfo, error)     { return os.Lstat(name) }
+func (osFiles) Open(name string) (fs.File, error)          { return os.Open(name) }
+func (osFiles) Readlink(name string) (string, error)       { return os.Readlink(name) }
+func (osFiles) Xattr(name, attr string) ([]byte, error)    { return getXattr(name, attr) }
+
+// osMetadata is the MetadataProvider for the real file system.
+type osMetadata struct{}
+
+func (osMetadata) Date(source, path string) (time.Time, error) {
+	return dateSources[source](path)
+}
+
+// osMover is the Mover for the real file system.
+type osMover struct{}
+
+func (osMover) Move(oldpath, newpath string) error { return moveFile(oldpath, newpath) }
+func (osMover) Copy(oldpath, newpath string) error { return copyItem(oldpath, newpath) }
+
+// MkdirAll is like os.MkdirAll, but when running as root,
+// it gives the folders it makes the owner of their existing parent.
+func (osMover) MkdirAll(dir string, perm fs.FileMode) error {
+	missing, parent := missingDirs(osFiles{}, dir)
+	if err := os.MkdirAll(dir, perm); err != nil {
+		return err
+	}
+	for _, d := range slices.Backward(missing) {
+		if err := chownLike(d, parent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// missingDirs returns dir and its parents that don't exist in src,
+// innermost first, and the nearest parent that does.
+func missingDirs(src FileSource, dir string) (missing []string, parent string) {
+	parent = dir
+	for {
+		if _, err := src.Lstat(parent); err == nil {
+			return missing, parent
+		}
+		missing = append(missing, parent)
+		if filepath.Dir(parent) == parent {
+			return missing, parent
+		}
+		parent = filepath.Dir(parent)
+	}
+}
+
+// subFS is the fs.FS of the files in dir from a FileSource,
+// so that they can be walked with fs.WalkDir.
+type subFS struct {
+	src FileSource
+	dir string
+}
+
+func (f subFS) path(op, name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return filepath.Join(f.dir, filepath.FromSlash(name)), nil
+}
+
+func (f subFS) Open(name string) (fs.File, error) {
+	path, err := f.path("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return f.src.Open(path)
+}
+
+func (f subFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	path, err := f.path("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	return f.src.ReadDir(path)
+}
+
+func (f subFS) Stat(name string) (fs.FileInfo, error) {
+	path, err := f.path("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return f.src.Lstat(path)
+}
+
+// readerAtFile is an open file that can be read at any offset,
+// like an *os.File or a file in a MemFS.
+type readerAtFile interface {
+	fs.File
+	io.ReaderAt
+}
+
+// openReaderAt opens name in src for reading at any offset.
+func openReaderAt(src FileSource, name string) (readerAtFile, fs.FileInfo, error) {
+	f, err := src.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	rf, ok := f.(readerAtFile)
+	if !ok {
+		f.Close()
+		return nil, nil, &fs.PathError{Op: "readat", Path: name, Err: errors.ErrUnsupported}
+	}
+	info, err := rf.Stat()
+	if err != nil {
+		rf.Close()
+		return nil, nil, err
+	}
+	return rf, info, nil
+}
@@ -0,0 +1,70 @@
+package mvfiles
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// runHooks runs -post-run-cmd and posts to -webhook after moves were executed.
+// Failing hooks are logged but don't fail the run.
+func (app *appEnv) runHooks(ctx context.Context, moves []Move, err error) {
+	if app.postRunCmd != "" {
+		if err := app.runPostRunCmd(ctx, moves); err != nil {
+			app.Warn("post-run command failed", "error", err)
+		}
+	}
+	if app.webhook != "" {
+		sum := app.newRunSummary(moves, err)
+		sum.Moves = moves
+		if err := postWebhook(ctx, app.webhook, sum); err != nil {
+			app.Warn("webhook failed", "url", app.webhook, "error", err)
+		}
+	}
+}
+
+// runPostRunCmd writes moves to a temporary CSV file and runs
+// app.postRunCmd in the shell with its path as the last argument.
+func (app *appEnv) runPostRunCmd(ctx context.Context, moves []Move) error {
+	f, err := os.CreateTemp("", "scooter-*.csv")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	err = writePlan(f, formatCSV, moves)
+	if err = errors.Join(err, f.Close()); err != nil {
+		return err
+	}
+	cmd := shellCommand(ctx, app.postRunCmd, f.Name())
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func postWebhook(ctx context.Context, url string, sum runSummary) error {
+	b, err := json.Marshal(sum)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status %s", res.Status)
+	}
+	return nil
+}
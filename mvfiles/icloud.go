@@ -0,0 +1,104 @@
+package mvfiles
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Ways to handle files in iCloud Drive that are not downloaded
+const (
+	ICloudSkip            = "skip"
+	ICloudMaterialize     = "materialize"
+	ICloudMovePlaceholder = "move-placeholder"
+)
+
+var icloudModes = []string{ICloudSkip, ICloudMaterialize, ICloudMovePlaceholder}
+
+// materializeTimeout is how long to wait for iCloud to download files.
+const materializeTimeout = 5 * time.Minute
+
+// icloudName returns the name of the file that a placeholder
+// named like .name.pdf.icloud stands in for.
+func icloudName(name string) (string, bool) {
+	hidden, ok := strings.CutSuffix(name, ".icloud")
+	if !ok || len(hidden) < 2 || hidden[0] != '.' {
+		return "", false
+	}
+	return hidden[1:], true
+}
+
+// isPlaceholder reports whether the file at path is not downloaded from iCloud,
+// either because it is a .icloud placeholder file or because it is dataless.
+func isPlaceholder(path string) bool {
+	if _, ok := icloudName(filepath.Base(path)); ok {
+		return true
+	}
+	return isDataless(path)
+}
+
+// skipHidden reports whether the item named name should be skipped
+// for being hidden. iCloud placeholders are hidden,
+// but they aren't skipped unless r.ICloud is ICloudSkip.
+func (r *runner) skipHidden(name string) bool {
+	if !strings.HasPrefix(name, ".") {
+		return false
+	}
+	_, ok := icloudName(name)
+	return !ok || r.ICloud == ICloudSkip
+}
+
+// handlePlaceholders returns paths with the iCloud placeholders left out
+// or, if r.ICloud is ICloudMaterialize, downloaded.
+// With ICloudMovePlaceholder, paths are returned as they are.
+func (r *runner) handlePlaceholders(paths []string) []string {
+	if r.ICloud == ICloudMovePlaceholder {
+		return paths
+	}
+	var kept, waiting []string
+	for _, path := range paths {
+		if !isPlaceholder(path) {
+			kept = append(kept, path)
+			continue
+		}
+		if r.ICloud == ICloudSkip {
+			r.Logger.Debug("skipping", "path", path, "reason", "not downloaded from iCloud")
+			continue
+		}
+		file := path
+		if name, ok := icloudName(filepath.Base(path)); ok {
+			file = filepath.Join(filepath.Dir(path), name)
+		}
+		if err := startDownload(file); err != nil {
+			r.Logger.Warn("could not download from iCloud", "path", file, "error", err)
+			continue
+		}
+		waiting = append(waiting, file)
+	}
+	if len(waiting) > 0 {
+		r.Logger.Info("waiting for iCloud downloads", "files", len(waiting))
+	}
+	deadline := time.Now().Add(materializeTimeout)
+	for len(waiting) > 0 {
+		var still []string
+		for _, path := range waiting {
+			if isPlaceholder(path) {
+				still = append(still, path)
+			} else {
+				kept = append(kept, path)
+			}
+		}
+		waiting = still
+		if len(waiting) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			for _, path := range waiting {
+				r.Logger.Warn("skipping", "path", path, "reason", "iCloud download timed out")
+			}
+			break
+		}
+		time.Sleep(time.Second)
+	}
+	return kept
+}
@@ -0,0 +1,35 @@
+package mvfiles
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// sfDataless is the file flag for files whose contents are in the cloud.
+const sfDataless = 0x40000000
+
+// isDataless reports whether the contents of the file at path
+// have been evicted to iCloud.
+func isDataless(path string) bool {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	return ok && st.Flags&sfDataless != 0
+}
+
+// startDownload asks iCloud to download the file at path.
+func startDownload(path string) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command("brctl", "download", path)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("brctl: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
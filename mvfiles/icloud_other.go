@@ -0,0 +1,15 @@
+//go:build !darwin
+
+package mvfiles
+
+import "errors"
+
+// isDataless reports whether the contents of the file at path
+// have been evicted to iCloud, which only happens on macOS.
+func isDataless(path string) bool {
+	return false
+}
+
+func startDownload(path string) error {
+	return errors.ErrUnsupported
+}
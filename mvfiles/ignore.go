@@ -0,0 +1,135 @@
+package mvfiles
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFile is the name of the file in a scanned directory
+// listing patterns for files to leave in place.
+const IgnoreFile = ".scooterignore"
+
+// defaultExcludes are partial downloads and files made by the system,
+// which should never be moved.
+var defaultExcludes = []string{
+	"*.aria2", "*.crdownload", "*.download", "*.opdownload", "*.part", "*.partial",
+	"desktop.ini", "Thumbs.db", "$RECYCLE.BIN/", "~$*",
+}
+
+// downloadControlExts are the extensions of files that downloaders keep
+// next to a download in progress, like aria2's name.aria2.
+var downloadControlExts = []string{".aria2"}
+
+// ignoreRule is one line of a .scooterignore file.
+type ignoreRule struct {
+	segments []string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// ignorer matches paths against gitignore style patterns.
+// The last matching pattern wins.
+type ignorer []ignoreRule
+
+// parseIgnore returns an ignorer for patterns using a subset of gitignore syntax:
+// blank lines and lines starting with # are skipped,
+// ! negates a pattern, a trailing / only matches directories,
+// patterns containing a / are relative to the scanned directory
+// while others match names at any depth, and ** matches any number of folders.
+func parseIgnore(patterns []string) (ignorer, error) {
+	var ig ignorer
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" || strings.HasPrefix(p, "#") {
+			continue
+		}
+		var rule ignoreRule
+		if rule.negate = strings.HasPrefix(p, "!"); rule.negate {
+			p = p[1:]
+		}
+		if rule.dirOnly = strings.HasSuffix(p, "/"); rule.dirOnly {
+			p = strings.TrimRight(p, "/")
+		}
+		rule.anchored = strings.Contains(p, "/")
+		p = strings.TrimPrefix(p, "/")
+		rule.segments = strings.Split(p, "/")
+		for _, seg := range rule.segments {
+			if _, err := path.Match(seg, ""); err != nil {
+				return nil, fmt.Errorf("bad exclude pattern %q: %w", p, err)
+			}
+		}
+		ig = append(ig, rule)
+	}
+	return ig, nil
+}
+
+// readIgnoreFile returns the lines of dir's IgnoreFile in src, if it exists.
+func readIgnoreFile(src FileSource, dir string) ([]string, error) {
+	f, err := src.Open(filepath.Join(dir, IgnoreFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var lines []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		lines = append(lines, s.Text())
+	}
+	return lines, s.Err()
+}
+
+// ignored reports whether name, a slash separated path
+// relative to the scanned directory, should be left in place.
+func (ig ignorer) ignored(name string, isDir bool) bool {
+	ignored := false
+	for _, rule := range ig {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.match(name) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+func (rule ignoreRule) match(name string) bool {
+	segments := strings.Split(name, "/")
+	if !rule.anchored {
+		ok, _ := path.Match(rule.segments[0], segments[len(segments)-1])
+		return ok
+	}
+	return matchSegments(rule.segments, segments)
+}
+
+// matchSegments matches path segments against pattern segments,
+// where a ** segment matches zero or more path segments.
+func matchSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := range len(segments) + 1 {
+				if matchSegments(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
@@ -0,0 +1,324 @@
+package mvfiles
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Journal actions
+const (
+	actionCopy   = "copy"
+	actionEnd    = "end"    // the run finished
+	actionIntent = "intent" // a move about to be made
+	actionLink   = "link"   // a symlink left in place of a moved file
+	actionMkdir  = "mkdir"
+	actionMove   = "move"
+	actionStart  = "start" // a run began, with its settings in new, like mode=copy&on-conflict=rename
+	actionTrash  = "trash"
+	actionUndo   = "undo"
+)
+
+// undoableActions are the actions that make up a run that can be undone.
+var undoableActions = []string{actionCopy, actionMove, actionTrash}
+
+var journalHeader = []string{"run", "time", "action", "old", "new", "checksum"}
+
+func defaultJournalPath() string {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "scooter", "journal.csv")
+}
+
+// journal is an append-only CSV log of the changes made by each run.
+// A nil *journal discards all records.
+type journal struct {
+	f   *os.File
+	w   *csv.Writer
+	run string
+}
+
+func openJournal(name string) (*journal, error) {
+	if name == "" {
+		return nil, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	j := &journal{
+		f:   f,
+		w:   csv.NewWriter(f),
+		run: newRunID(),
+	}
+	if info, err := f.Stat(); err == nil && info.Size() == 0 {
+		_ = j.w.Write(journalHeader)
+	}
+	return j, nil
+}
+
+// newRunID returns an ID for a run that sorts by when it started.
+func newRunID() string {
+	return time.Now().UTC().Format("20060102T150405.000000Z")
+}
+
+// record appends an entry for the current run and flushes it to disk.
+func (j *journal) record(action, oldpath, newpath string) error {
+	return j.recordSum(action, oldpath, newpath, "")
+}
+
+// recordSum is like record, but it includes the checksum of the file.
+func (j *journal) recordSum(action, oldpath, newpath, sum string) error {
+	if j == nil {
+		return nil
+	}
+	var err error
+	if oldpath != "" {
+		if oldpath, err = filepath.Abs(oldpath); err != nil {
+			return err
+		}
+	}
+	if newpath != "" {
+		if newpath, err = filepath.Abs(newpath); err != nil {
+			return err
+		}
+	}
+	return j.write(action, oldpath, newpath, sum)
+}
+
+// write appends an entry as is and flushes it to disk.
+func (j *journal) write(action, oldpath, newpath, sum string) error {
+	_ = j.w.Write([]string{
+		j.run, time.Now().Format(time.RFC3339), action, oldpath, newpath, sum,
+	})
+	j.w.Flush()
+	return j.w.Error()
+}
+
+// start records the settings a run needs to be resumed the way it began:
+// whether it copies or moves and its conflict strategy.
+func (j *journal) start(r *runner) error {
+	if j == nil {
+		return nil
+	}
+	mode := actionMove
+	if r.Copy {
+		mode = actionCopy
+	}
+	settings := url.Values{"mode": {mode}, "on-conflict": {r.OnConflict}}
+	return j.write(actionStart, "", settings.Encode(), "")
+}
+
+func (j *journal) Close() error {
+	if j == nil {
+		return nil
+	}
+	j.w.Flush()
+	return errors.Join(j.w.Error(), j.f.Close())
+}
+
+type journalEntry struct {
+	Run, Time, Action, Old, New, Checksum string
+}
+
+func readJournal(name string) ([]journalEntry, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cols := make(map[string]int, len(header))
+	for i, col := range header {
+		cols[col] = i
+	}
+	if _, ok := cols["checksum"]; !ok {
+		// Journals started before checksums have them after the last column
+		cols["checksum"] = len(header)
+	}
+	field := func(row []string, col string) string {
+		if i, ok := cols[col]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+	var entries []journalEntry
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, journalEntry{
+			Run:      field(row, "run"),
+			Time:     field(row, "time"),
+			Action:   field(row, "action"),
+			Old:      field(row, "old"),
+			New:      field(row, "new"),
+			Checksum: field(row, "checksum"),
+		})
+	}
+}
+
+// lastRun returns the entries of the most recent run
+// that still has moves or copies which have not been undone.
+func lastRun(entries []journalEntry) []journalEntry {
+	type key struct{ run, old, new string }
+	undone := make(map[key]bool)
+	for _, e := range entries {
+		if e.Action == actionUndo {
+			undone[key{e.Run, e.Old, e.New}] = true
+		}
+	}
+	run := ""
+	for _, e := range slices.Backward(entries) {
+		if slices.Contains(undoableActions, e.Action) && !undone[key{e.Run, e.Old, e.New}] {
+			run = e.Run
+			break
+		}
+	}
+	if run == "" {
+		return nil
+	}
+	var runEntries []journalEntry
+	for _, e := range entries {
+		if e.Run == run && !undone[key{e.Run, e.Old, e.New}] &&
+			e.Action != actionUndo && e.Action != actionIntent && e.Action != actionEnd &&
+			e.Action != actionStart {
+			runEntries = append(runEntries, e)
+		}
+	}
+	return runEntries
+}
+
+// defaultDirMode is the mode of the folders created for moves,
+// before the umask.
+const defaultDirMode = 0o755
+
+// mkdirAll makes dir and its parents with r.Mover
+// and records each directory it creates.
+func (r *runner) mkdirAll(j *journal, dir string) error {
+	missing, _ := missingDirs(r.Source, dir)
+	if err := r.Mover.MkdirAll(dir, r.DirMode); err != nil {
+		return err
+	}
+	for _, d := range slices.Backward(missing) {
+		if err := j.record(actionMkdir, "", d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Undo moves the files from the most recent run in the journal
+// back to their original locations, deletes the copies it made,
+// and removes the empty folders it created. If app.dir is set,
+// it uses the origins recorded on the files in app.dir instead.
+func (app *appEnv) Undo(ctx context.Context) (err error) {
+	if app.dir != "" {
+		return app.undoFromOrigins(ctx)
+	}
+	if app.opts.Journal == "" {
+		return errors.New("no journal file")
+	}
+	entries, err := readJournal(app.opts.Journal)
+	if err != nil {
+		return err
+	}
+	run := lastRun(entries)
+	if len(run) == 0 {
+		return errors.New("nothing to undo")
+	}
+	return app.undo(ctx, run)
+}
+
+// undo reverses the entries of a run.
+func (app *appEnv) undo(ctx context.Context, run []journalEntry) (err error) {
+	app.Info("undoing run", "run", run[0].Run)
+
+	if app.dryRun {
+		var moves []Move
+		for _, e := range slices.Backward(run) {
+			switch e.Action {
+			case actionMove, actionTrash:
+				moves = append(moves, Move{Old: e.New, New: e.Old})
+			case actionCopy:
+				moves = append(moves, Move{Old: e.New})
+			}
+		}
+		return app.printPlan(moves)
+	}
+
+	j, err := openJournal(app.opts.Journal)
+	if err != nil {
+		return err
+	}
+	j.run = run[0].Run
+	defer func() {
+		err = errors.Join(err, j.Close())
+	}()
+	for _, e := range slices.Backward(run) {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		switch e.Action {
+		case actionMove, actionTrash:
+			if _, err = os.Lstat(e.Old); err == nil {
+				return fmt.Errorf("cannot restore %q: %w", e.Old, fs.ErrExist)
+			}
+			if err = os.MkdirAll(filepath.Dir(e.Old), defaultDirMode); err != nil {
+				return err
+			}
+			if err = moveFile(e.New, e.Old); err != nil {
+				return err
+			}
+		case actionCopy:
+			// The original is still in place
+			if err = os.RemoveAll(e.New); err != nil {
+				return err
+			}
+		case actionLink:
+			if info, err := os.Lstat(e.New); err == nil && info.Mode()&fs.ModeSymlink != 0 {
+				if err = os.Remove(e.New); err != nil {
+					return err
+				}
+			}
+		case actionMkdir:
+			// Only succeeds if the folder is empty
+			if err := removeEmptyDir(e.New); err != nil {
+				app.Info("keeping folder", "path", e.New, "error", err)
+			}
+		}
+		if err = j.record(actionUndo, e.Old, e.New); err != nil {
+			return err
+		}
+	}
+	return nil
+}
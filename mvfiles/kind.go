@@ -0,0 +1,270 @@
+package mvfiles
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Ways to classify files
+const (
+	ClassifyExt = "ext"
+	ClassifyUTI = "uti"
+)
+
+var classifiers = []string{ClassifyExt, ClassifyUTI}
+
+var defaultKinds = []string{
+	"3d: 3mf blend fbx glb gltf obj stl usdz",
+	"app: app",
+	"archive: 7z bz gz rar tar tar.bz2 tar.gz tar.xz tar.zst tbz2 tgz xz zip zst",
+	"audio: aac aiff flac m4a mp3 ogg opus wav",
+	"book: azw azw3 epub mobi",
+	"code: c cc cpp go h hpp ipynb java kt py rb rs sh swift ts",
+	"data: csv json xls xlsx",
+	"disk-image: img iso sparsebundle sparseimage vhd vhdx vmdk",
+	"doc: doc docx pages pdf rtf rtfd txt",
+	"email: eml emlx",
+	"font: otf ttc ttf woff woff2",
+	"image: arw avif bmp cr2 cr3 dng gif heic jpg jpeg nef orf png raf rw2 svg tif webp",
+	"installer: deb dmg exe mpkg msi pkg rpm",
+	"subtitle: ass srt sub vtt",
+	"video: avi m4v mkv mov mp4 mpeg webm",
+	"web: css html ico js sass",
+}
+
+// screenshotName matches the names macOS gives screenshots,
+// like "Screenshot 2025-05-01 at 9.41.03 AM.png".
+var screenshotName = regexp.MustCompile(`^(Screenshot|Screen Shot) \d{4}-\d{2}-\d{2} at `)
+
+// Kinds classifies files by their extension.
+type Kinds struct {
+	exts        map[string]string
+	fallback    string
+	screenshots string
+	routes      map[string]string
+	hooks       []fileHook
+	sizeRoutes  []SizeRoute
+	companions  map[string][]string
+}
+
+// NewKinds returns the built-in classification.
+func NewKinds() *Kinds {
+	km := &Kinds{
+		exts:        make(map[string]string),
+		fallback:    "misc",
+		screenshots: "screenshots",
+		companions:  make(map[string][]string),
+	}
+	for _, s := range defaultKinds {
+		kind, fields, _ := strings.Cut(s, ":")
+		km.Add(kind, strings.Fields(fields)...)
+	}
+	for _, s := range defaultCompanions {
+		ext, fields, _ := strings.Cut(s, ":")
+		km.companions[ext] = strings.Fields(fields)
+	}
+	return km
+}
+
+// Add assigns exts to kind, replacing any previous assignment.
+func (km *Kinds) Add(kind string, exts ...string) {
+	for _, ext := range exts {
+		ext = strings.ToLower(strings.TrimPrefix(ext, "."))
+		km.exts[ext] = kind
+	}
+}
+
+// Kind returns the kind of the file name.
+// Compound extensions, like tar.gz, take precedence over their last part.
+func (km *Kinds) Kind(name string) string {
+	name = strings.ToLower(filepath.Base(name))
+	ext := filepath.Ext(name)
+	if inner := filepath.Ext(strings.TrimSuffix(name, ext)); inner != "" {
+		if kind, ok := km.exts[strings.TrimPrefix(inner+ext, ".")]; ok {
+			return kind
+		}
+	}
+	if kind, ok := km.exts[strings.TrimPrefix(ext, ".")]; ok {
+		return kind
+	}
+	return km.fallback
+}
+
+// isKind reports whether name is one of the kinds in km.
+func (km *Kinds) isKind(name string) bool {
+	if name == km.fallback || name == km.screenshots || name == packageKind {
+		return true
+	}
+	for _, kind := range km.exts {
+		if kind == name {
+			return true
+		}
+	}
+	_, ok := km.routes[name]
+	return ok
+}
+
+// names returns the kinds in km, sorted.
+func (km *Kinds) names() []string {
+	names := []string{km.fallback, packageKind}
+	if km.screenshots != "" {
+		names = append(names, km.screenshots)
+	}
+	names = slices.AppendSeq(names, maps.Values(km.exts))
+	names = slices.AppendSeq(names, maps.Keys(km.routes))
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// screenshotKind returns the kind for the file at path in src if it is a screenshot,
+// judging by its name or the attribute Spotlight gives to screen captures.
+// It returns the empty string for other files.
+func (km *Kinds) screenshotKind(src FileSource, path, kind string) string {
+	if km.screenshots == "" {
+		return ""
+	}
+	if screenshotName.MatchString(filepath.Base(path)) {
+		return km.screenshots
+	}
+	// Only images can be screen captures
+	if kind != km.Kind("x.png") {
+		return ""
+	}
+	// A binary property list holding a single true value
+	value, err := src.Xattr(path, "com.apple.metadata:kMDItemIsScreenCapture")
+	if err == nil && len(value) > 8 && string(value[:8]) == "bplist00" && value[8] == 0x09 {
+		return km.screenshots
+	}
+	return ""
+}
+
+// kindsConfig is the format of the kinds file:
+//
+//	default = "other"
+//	screenshots = "images" # or "" to treat them like other images
+//
+//	[kinds]
+//	font = ["otf", "ttf", "woff2"]
+//	image = ["raw"]
+//
+//	[routes]
+//	image = "~/Pictures/Inbox"
+//	audio = "~/Music/Inbox"
+//
+//	[size-routes]
+//	">2GB" = "large"
+//	"video>500MB" = "/Volumes/Archive/Video"
+//
+//	[companions]
+//	xmp = ["*"] # follows a file of any extension
+//	srt = ["mkv", "mp4"]
+//	aae = [] # never a companion
+//
+//	[[hooks]]
+//	exts = ["pdf"]
+//	before = ["ocrmypdf", "--skip-text", "{{.Source}}", "{{.Source}}"]
+//
+// Extensions listed in the file are added to the built-in kinds,
+// and take precedence over them. Routes send files of a kind
+// to a different destination root, and size routes do the same
+// for big files (see SizeRoute). Companions, like sidecars, move along with
+// the file with the same name and one of the listed extensions.
+// Hooks run commands before or after moving files (see hookConfig).
+type kindsConfig struct {
+	Default     string              `toml:"default"`
+	Screenshots *string             `toml:"screenshots"`
+	Kinds       map[string][]string `toml:"kinds"`
+	Routes      map[string]string   `toml:"routes"`
+	SizeRoutes  map[string]string   `toml:"size-routes"`
+	Companions  map[string][]string `toml:"companions"`
+	Hooks       []hookConfig        `toml:"hooks"`
+}
+
+// LoadKinds returns the built-in kinds updated by the TOML file name, if it exists.
+func LoadKinds(name string) (*Kinds, error) {
+	km := NewKinds()
+	if name == "" {
+		return km, nil
+	}
+	var conf kindsConfig
+	md, err := toml.DecodeFile(name, &conf)
+	if errors.Is(err, fs.ErrNotExist) {
+		return km, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading kinds: %w", err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("loading kinds: unknown key %q in %q", undecoded[0], name)
+	}
+	if conf.Default != "" {
+		km.fallback = conf.Default
+	}
+	if conf.Screenshots != nil {
+		km.screenshots = *conf.Screenshots
+	}
+	for kind, exts := range conf.Kinds {
+		km.Add(kind, exts...)
+	}
+	km.routes = conf.Routes
+	for rule, dir := range conf.SizeRoutes {
+		sr, err := ParseSizeRoute(rule + "=" + dir)
+		if err != nil {
+			return nil, fmt.Errorf("loading kinds: %w", err)
+		}
+		km.sizeRoutes = append(km.sizeRoutes, sr)
+	}
+	sortSizeRoutes(km.sizeRoutes)
+	for ext, exts := range conf.Companions {
+		ext = strings.ToLower(strings.TrimPrefix(ext, "."))
+		if len(exts) == 0 {
+			delete(km.companions, ext)
+			continue
+		}
+		km.companions[ext] = nil
+		for _, e := range exts {
+			km.companions[ext] = append(km.companions[ext], strings.ToLower(strings.TrimPrefix(e, ".")))
+		}
+	}
+	for i, hc := range conf.Hooks {
+		h, err := parseFileHook(hc)
+		if err != nil {
+			return nil, fmt.Errorf("loading kinds: hook %d in %q: %w", i+1, name, err)
+		}
+		km.hooks = append(km.hooks, h)
+	}
+	return km, nil
+}
+
+// expandHome replaces a leading ~ in path with the user's home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+func defaultConfigPath(name string) string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "scooter", name)
+}
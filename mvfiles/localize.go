@@ -0,0 +1,106 @@
+package mvfiles
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// LocalizedDir is the name of the folder of translations that the Finder
+// uses to show a folder's name in the user's language.
+const LocalizedDir = ".localized"
+
+// kindNames are the display names of the built-in kinds by language code.
+var kindNames = map[string]map[string]string{
+	"en": {
+		"3d": "3D", "app": "Applications", "archive": "Archives", "audio": "Audio", "book": "Books",
+		"code": "Code", "data": "Data", "disk-image": "Disk Images", "doc": "Documents", "email": "Email",
+		"font": "Fonts", "image": "Images", "installer": "Installers", "misc": "Other",
+		"package": "Packages", "screenshots": "Screenshots", "subtitle": "Subtitles", "video": "Videos",
+		"web": "Web",
+	},
+	"de": {
+		"3d": "3D", "app": "Programme", "archive": "Archive", "audio": "Audio", "book": "Bücher",
+		"code": "Code", "data": "Daten", "disk-image": "Image-Dateien", "doc": "Dokumente",
+		"email": "E-Mails", "font": "Schriften", "image": "Bilder", "installer": "Installationsprogramme",
+		"misc": "Sonstiges", "package": "Pakete", "screenshots": "Bildschirmfotos",
+		"subtitle": "Untertitel", "video": "Videos", "web": "Web",
+	},
+	"es": {
+		"3d": "3D", "app": "Aplicaciones", "archive": "Archivos comprimidos", "audio": "Audio",
+		"book": "Libros", "code": "Código", "data": "Datos", "disk-image": "Imágenes de disco",
+		"doc": "Documentos", "email": "Correo", "font": "Tipos de letra", "image": "Imágenes",
+		"installer": "Instaladores", "misc": "Otros", "package": "Paquetes",
+		"screenshots": "Capturas de pantalla", "subtitle": "Subtítulos", "video": "Vídeos", "web": "Web",
+	},
+	"fr": {
+		"3d": "3D", "app": "Applications", "archive": "Archives", "audio": "Audio", "book": "Livres",
+		"code": "Code", "data": "Données", "disk-image": "Images disque", "doc": "Documents",
+		"email": "E-mails", "font": "Polices", "image": "Images",
+		"installer": "Programmes d’installation", "misc": "Autres", "package": "Paquets",
+		"screenshots": "Captures d’écran", "subtitle": "Sous-titres", "video": "Vidéos", "web": "Web",
+	},
+	"it": {
+		"3d": "3D", "app": "Applicazioni", "archive": "Archivi", "audio": "Audio", "book": "Libri",
+		"code": "Codice", "data": "Dati", "disk-image": "Immagini disco", "doc": "Documenti",
+		"email": "Email", "font": "Font", "image": "Immagini", "installer": "Programmi di installazione",
+		"misc": "Altro", "package": "Pacchetti", "screenshots": "Istantanee schermo",
+		"subtitle": "Sottotitoli", "video": "Video", "web": "Web",
+	},
+	"ja": {
+		"3d": "3D", "app": "アプリケーション", "archive": "アーカイブ", "audio": "オーディオ", "book": "ブック", "code": "コード",
+		"data": "データ", "disk-image": "ディスクイメージ", "doc": "書類", "email": "メール", "font": "フォント",
+		"image": "イメージ", "installer": "インストーラ", "misc": "その他", "package": "パッケージ",
+		"screenshots": "スクリーンショット", "subtitle": "字幕", "video": "ビデオ", "web": "Web",
+	},
+}
+
+// localizeKindDir adds translations of kind to the folder for it
+// that holds newpath, unless it already has them.
+func (r *runner) localizeKindDir(newpath, kind string, size int64) error {
+	root := r.root(kind, size)
+	dir := filepath.Dir(newpath)
+	for filepath.Base(dir) != kind {
+		parent := filepath.Dir(dir)
+		if parent == dir || !strings.HasPrefix(parent, root) {
+			return nil
+		}
+		dir = parent
+	}
+	if _, ok := kindNames["en"][kind]; !ok {
+		return nil
+	}
+	loc := filepath.Join(dir, LocalizedDir)
+	if _, err := os.Lstat(loc); err == nil {
+		return nil
+	}
+	if err := os.Mkdir(loc, 0o755); err != nil {
+		return err
+	}
+	for _, lang := range slices.Sorted(maps.Keys(kindNames)) {
+		s := fmt.Sprintf("%q = %q;\n", kind, kindNames[lang][kind])
+		if err := os.WriteFile(filepath.Join(loc, lang+".strings"), []byte(s), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeEmptyDir removes dir if it holds nothing but junk files.
+func removeEmptyDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if !slices.Contains(junkFiles, e.Name()) {
+			return &fs.PathError{Op: "remove", Path: dir, Err: errors.New("directory not empty")}
+		}
+	}
+	return os.RemoveAll(dir)
+}
@@ -0,0 +1,35 @@
+package mvfiles
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"time"
+)
+
+// LockFile is the name of the file locked in a directory
+// while it is being organized.
+const LockFile = ".scooter.lock"
+
+var errLocked = errors.New("another scooter is organizing the directory")
+
+// lockDir locks dir against other runs and returns a function to unlock it.
+// If wait is set, lockDir waits until the lock is free or ctx is canceled.
+// Otherwise, it fails if another run holds the lock.
+func lockDir(ctx context.Context, dir string, wait bool) (unlock func() error, err error) {
+	name := filepath.Join(dir, LockFile)
+	for {
+		f, err := lockFile(name)
+		if err == nil {
+			return f.Close, nil
+		}
+		if !errors.Is(err, errLocked) || !wait {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
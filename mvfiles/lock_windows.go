@@ -0,0 +1,29 @@
+package mvfiles
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+const errorSharingViolation syscall.Errno = 32
+
+// lockFile opens name without sharing, so that no one else
+// can open it until the file is closed or the process exits.
+func lockFile(name string) (*os.File, error) {
+	p, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return nil, err
+	}
+	h, err := syscall.CreateFile(p,
+		syscall.GENERIC_READ|syscall.GENERIC_WRITE, 0, nil,
+		syscall.OPEN_ALWAYS, syscall.FILE_ATTRIBUTE_HIDDEN, 0)
+	if errors.Is(err, errorSharingViolation) {
+		return nil, fmt.Errorf("%w: %s is locked", errLocked, name)
+	}
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: name, Err: err}
+	}
+	return os.NewFile(uintptr(h), name), nil
+}
@@ -0,0 +1,46 @@
+package mvfiles
+
+import (
+	"flag"
+	"io"
+	"log/slog"
+	"os"
+)
+
+var logLevels = map[string]slog.Level{
+	"debug": slog.LevelDebug,
+	"info":  slog.LevelInfo,
+	"warn":  slog.LevelWarn,
+	"error": slog.LevelError,
+}
+
+func (app *appEnv) logFlags(fl *flag.FlagSet) {
+	choiceVar(fl, &app.logLevel, "log-level", "warn", "minimum `level` of messages to log", "debug", "info", "warn", "error")
+	choiceVar(fl, &app.logFormat, "log-format", "text", "`format` of log messages", "text", "json")
+	fl.StringVar(&app.logFile, "log-file", "", "`file` to append log messages to instead of stderr")
+	fl.BoolVar(&app.verbose, "verbose", false, "log debug output (same as -log-level debug)")
+}
+
+// setupLogger creates app.Logger from the logging options.
+func (app *appEnv) setupLogger() error {
+	var w io.Writer = os.Stderr
+	if app.logFile != "" {
+		// Left open until the program exits
+		f, err := os.OpenFile(expandHome(app.logFile), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+		if err != nil {
+			return err
+		}
+		w = f
+	}
+	level := logLevels[app.logLevel]
+	if app.verbose {
+		level = slog.LevelDebug
+	}
+	opts := &slog.HandlerOptions{Level: level}
+	var h slog.Handler = slog.NewTextHandler(w, opts)
+	if app.logFormat == "json" {
+		h = slog.NewJSONHandler(w, opts)
+	}
+	app.Logger = slog.New(h)
+	return nil
+}
@@ -0,0 +1,27 @@
+package mvfiles
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// mdfind returns the paths in dir and its subdirectories
+// that match the Spotlight query.
+func mdfind(dir, query string) ([]string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("mdfind", "-0", "-onlyin", dir, query)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("mdfind: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	var paths []string
+	for _, p := range bytes.Split(out, []byte{0}) {
+		if len(p) > 0 {
+			paths = append(paths, string(p))
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,9 @@
+//go:build !darwin
+
+package mvfiles
+
+import "errors"
+
+func mdfind(dir, query string) ([]string, error) {
+	return nil, errors.New("Spotlight queries require macOS")
+}
@@ -0,0 +1,275 @@
+package mvfiles
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// MemFS is an in-memory file system that can stand in for the real one
+// as the FileSource, MetadataProvider, and Mover of Options, so that plans
+// can be tried out and tested without touching any files. Every date source
+// but "filename" gives the modification time of a file.
+// It has no links. Options that need the real file system, like TagKinds
+// and ClassifyUTI, still use it.
+// The zero value is an empty file system ready to use.
+type MemFS struct {
+	// Now returns the modification time of the folders made by MkdirAll.
+	// It defaults to time.Now.
+	Now   func() time.Time
+	mu    sync.Mutex
+	files map[string]*memFile // by clean path
+}
+
+type memFile struct {
+	data    []byte
+	modTime time.Time
+	isDir   bool
+	xattrs  map[string][]byte
+}
+
+// WriteFile creates or replaces the file at name, making its parent folders.
+func (m *MemFS) WriteFile(name string, data []byte, modTime time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = filepath.Clean(name)
+	if err := m.mkdirAll(filepath.Dir(name), modTime); err != nil {
+		return err
+	}
+	if f := m.files[name]; f != nil && f.isDir {
+		return &fs.PathError{Op: "write", Path: name, Err: syscall.EISDIR}
+	}
+	m.files[name] = &memFile{data: slices.Clone(data), modTime: modTime}
+	return nil
+}
+
+func (m *MemFS) MkdirAll(dir string, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now
+	if m.Now != nil {
+		now = m.Now
+	}
+	return m.mkdirAll(filepath.Clean(dir), now())
+}
+
+func (m *MemFS) mkdirAll(dir string, modTime time.Time) error {
+	if m.files == nil {
+		m.files = make(map[string]*memFile)
+	}
+	for d := dir; !isMemRoot(d); d = filepath.Dir(d) {
+		if f := m.files[d]; f != nil {
+			if !f.isDir {
+				return &fs.PathError{Op: "mkdir", Path: d, Err: syscall.ENOTDIR}
+			}
+			break
+		}
+		m.files[d] = &memFile{modTime: modTime, isDir: true}
+	}
+	return nil
+}
+
+// isMemRoot reports whether name is the working directory or a root,
+// which always exist.
+func isMemRoot(name string) bool {
+	return name == "." || filepath.Dir(name) == name
+}
+
+// lookup returns the file at the clean path name.
+func (m *MemFS) lookup(op, name string) (*memFile, error) {
+	if isMemRoot(name) {
+		return &memFile{isDir: true}, nil
+	}
+	// Parents must be folders
+	for d := filepath.Dir(name); !isMemRoot(d); d = filepath.Dir(d) {
+		if f := m.files[d]; f != nil && !f.isDir {
+			return nil, &fs.PathError{Op: op, Path: name, Err: syscall.ENOTDIR}
+		}
+	}
+	f := m.files[name]
+	if f == nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return f, nil
+}
+
+func (m *MemFS) Lstat(name string) (fs.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = filepath.Clean(name)
+	f, err := m.lookup("lstat", name)
+	if err != nil {
+		return nil, err
+	}
+	return memInfo{filepath.Base(name), f}, nil
+}
+
+func (m *MemFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = filepath.Clean(name)
+	f, err := m.lookup("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !f.isDir {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: syscall.ENOTDIR}
+	}
+	var entries []fs.DirEntry
+	for path, f := range m.files {
+		if filepath.Dir(path) == name {
+			entries = append(entries, fs.FileInfoToDirEntry(memInfo{filepath.Base(path), f}))
+		}
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return entries, nil
+}
+
+func (m *MemFS) Open(name string) (fs.File, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = filepath.Clean(name)
+	f, err := m.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return &memOpenFile{memInfo{filepath.Base(name), f}, bytes.NewReader(f.data)}, nil
+}
+
+func (m *MemFS) Readlink(name string) (string, error) {
+	if _, err := m.Lstat(name); err != nil {
+		return "", err
+	}
+	return "", &fs.PathError{Op: "readlink", Path: name, Err: syscall.EINVAL}
+}
+
+// errNoXattr is returned by MemFS for a missing extended attribute.
+var errNoXattr = errors.New("no such attribute")
+
+func (m *MemFS) Xattr(name, attr string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = filepath.Clean(name)
+	f, err := m.lookup("getxattr", name)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := f.xattrs[attr]
+	if !ok {
+		return nil, &fs.PathError{Op: "getxattr", Path: name, Err: errNoXattr}
+	}
+	return slices.Clone(value), nil
+}
+
+// SetXattr sets the extended attribute attr of the file at name,
+// like the quarantine or the URLs it was downloaded from.
+func (m *MemFS) SetXattr(name, attr string, value []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = filepath.Clean(name)
+	f, err := m.lookup("setxattr", name)
+	if err != nil {
+		return err
+	}
+	if f.xattrs == nil {
+		f.xattrs = make(map[string][]byte)
+	}
+	f.xattrs[attr] = slices.Clone(value)
+	return nil
+}
+
+func (m *MemFS) Date(source, path string) (time.Time, error) {
+	if source == "filename" {
+		return getFilenameDate(path)
+	}
+	info, err := m.Lstat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+func (m *MemFS) Move(oldpath, newpath string) error {
+	return m.transfer("rename", oldpath, newpath, true)
+}
+
+func (m *MemFS) Copy(oldpath, newpath string) error {
+	return m.transfer("copy", oldpath, newpath, false)
+}
+
+// transfer copies the file or tree at oldpath to newpath,
+// replacing a file there, and then removes oldpath if remove is set.
+func (m *MemFS) transfer(op, oldpath, newpath string, remove bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	oldpath, newpath = filepath.Clean(oldpath), filepath.Clean(newpath)
+	if _, err := m.lookup(op, oldpath); err != nil {
+		return err
+	}
+	parent, err := m.lookup(op, filepath.Dir(newpath))
+	if err != nil {
+		return err
+	}
+	if !parent.isDir {
+		return &fs.PathError{Op: op, Path: newpath, Err: syscall.ENOTDIR}
+	}
+	if f := m.files[newpath]; f != nil && f.isDir {
+		return &fs.PathError{Op: op, Path: newpath, Err: fs.ErrExist}
+	}
+	if oldpath == newpath {
+		return nil
+	}
+	prefix := oldpath + string(filepath.Separator)
+	moved := make(map[string]*memFile)
+	for path, f := range m.files {
+		if path == oldpath || strings.HasPrefix(path, prefix) {
+			moved[path] = f
+		}
+	}
+	for path, f := range moved {
+		if remove {
+			delete(m.files, path)
+		}
+		dup := *f
+		dup.xattrs = maps.Clone(f.xattrs)
+		m.files[newpath+strings.TrimPrefix(path, oldpath)] = &dup
+	}
+	return nil
+}
+
+// memInfo is the fs.FileInfo of a file in a MemFS.
+type memInfo struct {
+	name string
+	f    *memFile
+}
+
+func (fi memInfo) Name() string       { return fi.name }
+func (fi memInfo) Size() int64        { return int64(len(fi.f.data)) }
+func (fi memInfo) ModTime() time.Time { return fi.f.modTime }
+func (fi memInfo) IsDir() bool        { return fi.f.isDir }
+func (fi memInfo) Sys() any           { return nil }
+
+func (fi memInfo) Mode() fs.FileMode {
+	if fi.f.isDir {
+		return fs.ModeDir | 0o755
+	}
+	return 0o644
+}
+
+// memOpenFile is an open file in a MemFS.
+type memOpenFile struct {
+	info memInfo
+	*bytes.Reader
+}
+
+func (f *memOpenFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *memOpenFile) Close() error               { return nil }
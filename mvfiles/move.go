@@ -0,0 +1,209 @@
+package mvfiles
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"syscall"
+	"time"
+)
+
+// moveFile renames oldpath to newpath. If they are on different volumes,
+// it copies oldpath to newpath and then removes oldpath.
+func moveFile(oldpath, newpath string) error {
+	err := os.Rename(oldpath, newpath)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	if err = copyItem(oldpath, newpath); err != nil {
+		return err
+	}
+	return os.RemoveAll(oldpath)
+}
+
+// copyItem copies oldpath next to newpath and renames the copy into place,
+// so that newpath is never left half written. The copy is a clone
+// if the volume supports it.
+func copyItem(oldpath, newpath string) error {
+	dir := filepath.Dir(newpath)
+	tmp := filepath.Join(dir, tempName(dir, filepath.Base(newpath)))
+	_ = os.RemoveAll(tmp)
+	if err := cloneFile(oldpath, tmp); err != nil {
+		_ = os.RemoveAll(tmp)
+		if err = copyAll(oldpath, tmp); err != nil {
+			_ = os.RemoveAll(tmp)
+			return err
+		}
+	}
+	if err := os.Rename(tmp, newpath); err != nil {
+		_ = os.RemoveAll(tmp)
+		return err
+	}
+	return nil
+}
+
+// copyAll copies the file or directory tree at src to dst,
+// preserving permissions, modification times, and extended attributes.
+// File contents are verified by checksum after copying.
+func copyAll(src, dst string) error {
+	type dirInfo struct {
+		path string
+		info fs.FileInfo
+	}
+	var dirs []dirInfo
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			if err = os.Mkdir(target, 0o700); err != nil {
+				return err
+			}
+			dirs = append(dirs, dirInfo{target, info})
+			return copyXattrs(path, target)
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			if err = os.Symlink(link, target); err != nil {
+				return err
+			}
+			return copyXattrs(path, target)
+		case d.Type().IsRegular():
+			return copyFile(path, target, info)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// Set directory metadata last, deepest first,
+	// since copying their contents changes their modification times.
+	for _, dir := range slices.Backward(dirs) {
+		if err = setMetadata(dir.path, dir.info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(src, dst string, info fs.FileInfo) error {
+	sum, err := writeCopy(src, dst)
+	if err != nil {
+		return err
+	}
+	if err = copyXattrs(src, dst); err != nil {
+		return err
+	}
+	if err = setMetadata(dst, info); err != nil {
+		return err
+	}
+	copySum, err := checksum(dst)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(sum, copySum) {
+		return fmt.Errorf("copying %q: checksum mismatch", src)
+	}
+	return nil
+}
+
+// writeCopy copies the contents of src to a new file dst
+// and returns the checksum of the data read from src.
+func writeCopy(src, dst string) (sum []byte, err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errors.Join(err, out.Close())
+	}()
+	h := sha256.New()
+	if _, err = io.Copy(io.MultiWriter(out, h), in); err != nil {
+		return nil, err
+	}
+	if err = out.Sync(); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+func checksum(name string) ([]byte, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+func setMetadata(name string, info fs.FileInfo) error {
+	if err := os.Chmod(name, info.Mode()&(fs.ModePerm|fs.ModeSetuid|fs.ModeSetgid|fs.ModeSticky)); err != nil {
+		return err
+	}
+	return os.Chtimes(name, time.Time{}, info.ModTime())
+}
+
+// unsettableXattrs are managed by the system and can't be copied.
+var unsettableXattrs = []string{
+	"com.apple.macl",
+	"com.apple.provenance",
+	"com.apple.rootless",
+}
+
+// copyXattrs copies the extended attributes of src to dst,
+// which include Finder tags and comments, quarantine flags, and resource forks,
+// and then checks that dst has the same attributes as src.
+func copyXattrs(src, dst string) error {
+	names, err := listXattrs(src)
+	if err != nil {
+		return err
+	}
+	want := make(map[string][]byte, len(names))
+	for _, name := range names {
+		if slices.Contains(unsettableXattrs, name) {
+			continue
+		}
+		value, err := getXattr(src, name)
+		if err != nil {
+			return err
+		}
+		if err = setXattr(dst, name, value); err != nil {
+			return err
+		}
+		want[name] = value
+	}
+	for name, value := range want {
+		got, err := getXattr(dst, name)
+		if err != nil || !bytes.Equal(got, value) {
+			return fmt.Errorf("copying %q: extended attribute %q not preserved", src, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package mvfiles
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+const (
+	testTagsXattr = xattrPrefix + "com.apple.metadata:_kMDItemUserTags"
+	testUserXattr = xattrPrefix + "com.example.scooter-test"
+)
+
+// setTestXattr sets an extended attribute on path,
+// skipping the test where the file system doesn't support them.
+func setTestXattr(t *testing.T, path, name string, value []byte) {
+	t.Helper()
+	if err := setXattr(path, name, value); err != nil {
+		if errors.Is(err, errors.ErrUnsupported) {
+			t.Skipf("extended attributes not supported: %v", err)
+		}
+		t.Fatal(err)
+	}
+}
+
+func checkXattr(t *testing.T, path, name string, want []byte) {
+	t.Helper()
+	got, err := getXattr(path, name)
+	if err != nil {
+		t.Fatalf("getting %s of %s: %v", name, path, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s of %s = %q, want %q", name, path, got, want)
+	}
+}
+
+var (
+	testTags  = []byte("bplist00\xa1\x01UBlue\n4\x08\n")
+	testValue = []byte("hello")
+)
+
+func TestCopyItemXattrs(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.txt")
+	dst := filepath.Join(dir, "dst.txt")
+	if err := os.WriteFile(src, []byte("contents"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	setTestXattr(t, src, testTagsXattr, testTags)
+	setTestXattr(t, src, testUserXattr, testValue)
+	mtime := time.Date(2020, 5, 4, 3, 2, 1, 0, time.UTC)
+	if err := os.Chtimes(src, time.Time{}, mtime); err != nil {
+		t.Fatal(err)
+	}
+	if err := copyItem(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	checkXattr(t, dst, testTagsXattr, testTags)
+	checkXattr(t, dst, testUserXattr, testValue)
+	info, err := os.Stat(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(mtime) {
+		t.Errorf("modification time = %v, want %v", info.ModTime(), mtime)
+	}
+	if b, _ := os.ReadFile(dst); string(b) != "contents" {
+		t.Errorf("contents = %q", b)
+	}
+}
+
+func TestCopyAllXattrs(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	dst := filepath.Join(dir, "dst")
+	if err := os.MkdirAll(filepath.Join(src, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join("sub", "file.txt")
+	if err := os.WriteFile(filepath.Join(src, file), []byte("contents"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	setTestXattr(t, src, testTagsXattr, testTags)
+	setTestXattr(t, filepath.Join(src, file), testTagsXattr, testTags)
+	setTestXattr(t, filepath.Join(src, file), testUserXattr, testValue)
+	if err := copyAll(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	checkXattr(t, dst, testTagsXattr, testTags)
+	checkXattr(t, filepath.Join(dst, file), testTagsXattr, testTags)
+	checkXattr(t, filepath.Join(dst, file), testUserXattr, testValue)
+}
+
+func TestCopyXattrsSkipsUnsettable(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.txt")
+	dst := filepath.Join(dir, "dst.txt")
+	for _, name := range []string{src, dst} {
+		if err := os.WriteFile(name, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	unsettable := xattrPrefix + "com.example.scooter-unsettable"
+	setTestXattr(t, src, unsettable, testValue)
+	setTestXattr(t, src, testUserXattr, testValue)
+	saved := unsettableXattrs
+	unsettableXattrs = append(slices.Clone(saved), unsettable)
+	t.Cleanup(func() { unsettableXattrs = saved })
+	if err := copyXattrs(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	checkXattr(t, dst, testUserXattr, testValue)
+	names, err := listXattrs(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slices.Contains(names, unsettable) {
+		t.Errorf("copied %s, which is unsettable", unsettable)
+	}
+}
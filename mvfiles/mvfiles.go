@@ -1,51 +1,281 @@
+// Package mvfiles scoots files into folders by date and kind.
 package mvfiles
 
 import (
-	"cmp"
-	"encoding/csv"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"io"
-	"log"
-	"math"
+	"io/fs"
+	"log/slog"
 	"os"
-	"path"
-	"path/filepath"
-	"slices"
+	"runtime"
+	"strconv"
 	"strings"
 	"time"
-	"unsafe"
 
+	"github.com/carlmjohnson/exitcode"
 	"github.com/carlmjohnson/flagx"
 	"github.com/carlmjohnson/versioninfo"
-	"github.com/progrium/darwinkit/macos/foundation"
-	"github.com/progrium/darwinkit/objc"
 )
 
 const AppName = "Scooter"
 
-func CLI(args []string) error {
+// Exit statuses, besides 0 for success
+const (
+	exitUsage    = 1 // also used for other errors
+	exitMetadata = 2 // a file's date couldn't be looked up
+	exitPartial  = 3 // some moves failed
+	exitNothing  = 4 // nothing matched
+)
+
+// errNothing is returned when there is nothing to move.
+var errNothing = exitcode.Set(errors.New("nothing to move"), exitNothing)
+
+func CLI(ctx context.Context, args []string) error {
 	var app appEnv
-	err := app.ParseArgs(args)
+	cmd, args := lookupCommand(args)
+	err := app.ParseArgs(cmd, args)
 	if err != nil {
 		return err
 	}
-	if err = app.Exec(); err != nil {
+	if err = cmd.run(&app, ctx); err != nil && !errors.Is(err, errNothing) {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 	}
 	return err
 }
 
-func (app *appEnv) ParseArgs(args []string) error {
-	fl := flag.NewFlagSet(AppName, flag.ContinueOnError)
-	fl.StringVar(&app.dir, "dir", ".", "directory to read")
-	fl.BoolVar(&app.excludeDirs, "exclude-dirs", false, "don't move directories")
-	fl.BoolVar(&app.dryRun, "dry-run", false, "just output file locations without moving")
-	app.Logger = log.New(io.Discard, AppName+" ", log.LstdFlags)
-	flagx.BoolFunc(fl, "verbose", "log debug output", func() error {
-		app.Logger.SetOutput(os.Stderr)
-		return nil
-	})
+type command struct {
+	name  string
+	args  string
+	nargs int // -1 for any number of arguments
+	// multiDir means -dir may be repeated to organize several directories at once
+	multiDir bool
+	// pathArgs means the arguments are paths or glob patterns of items to move
+	pathArgs bool
+	summary  string
+	flags    func(app *appEnv, fl *flag.FlagSet)
+	run      func(app *appEnv, ctx context.Context) error
+}
+
+var commands = []command{
+	{
+		name:     "move",
+		args:     " [path or glob...]",
+		nargs:    -1,
+		multiDir: true,
+		pathArgs: true,
+		summary:  "organize files by date and kind (the default)",
+		flags: func(app *appEnv, fl *flag.FlagSet) {
+			app.planFlags(fl)
+			app.executeFlags(fl)
+			app.outputFlags(fl, true)
+			fl.BoolVar(&app.interactive, "interactive", false, "review the plan at a line prompt and choose which moves to make before moving")
+			fl.BoolVar(&app.confirm, "confirm", false, "summarize the plan and ask before moving")
+			fl.BoolVar(&app.pruneEmpty, "prune-empty", false, "remove empty folders in -dir after moving")
+			app.settleFlag(fl, 0)
+		},
+		run: (*appEnv).Exec,
+	},
+	{
+		name:     "plan",
+		args:     " [path or glob...]",
+		nargs:    -1,
+		multiDir: true,
+		pathArgs: true,
+		summary:  "list the moves that move would make",
+		flags: func(app *appEnv, fl *flag.FlagSet) {
+			app.planFlags(fl)
+			app.outputFlags(fl, false)
+			app.settleFlag(fl, 0)
+			fl.StringVar(&app.diff, "diff", "", "saved plan `file` to compare with, listing the files added, removed, or sent somewhere else instead of the plan")
+			app.dryRun = true
+		},
+		run: (*appEnv).Exec,
+	},
+	{
+		name:    "apply",
+		args:    " <plan.csv>",
+		nargs:   1,
+		summary: "check and execute a plan saved from plan or -dry-run",
+		flags: func(app *appEnv, fl *flag.FlagSet) {
+			app.executeFlags(fl)
+			app.outputFlags(fl, true)
+		},
+		run: (*appEnv).Apply,
+	},
+	{
+		name:    "undo",
+		summary: "move the files from the last run back to where they came from",
+		flags: func(app *appEnv, fl *flag.FlagSet) {
+			app.journalFlag(fl)
+			app.outputFlags(fl, true)
+			fl.StringVar(&app.dir, "dir", "", "undo using the origins recorded on the files in `directory` instead of the journal")
+		},
+		run: (*appEnv).Undo,
+	},
+	{
+		name:    "resume",
+		summary: "finish the last run if it was interrupted",
+		flags: func(app *appEnv, fl *flag.FlagSet) {
+			app.executeFlags(fl)
+			app.outputFlags(fl, true)
+			fl.BoolVar(&app.rollback, "rollback", false, "undo the interrupted run instead of finishing it")
+		},
+		run: (*appEnv).Resume,
+	},
+	{
+		name:    "where",
+		args:    " <pattern>",
+		nargs:   1,
+		summary: "find where files whose original names match a glob pattern went",
+		flags: func(app *appEnv, fl *flag.FlagSet) {
+			app.journalFlag(fl)
+			fl.StringVar(&app.dir, "dir", "", "search the origins recorded on the files in `directory` instead of the journal")
+		},
+		run: (*appEnv).Where,
+	},
+	{
+		name:    "verify",
+		summary: "re-hash the files moved with -checksum to check that they are intact",
+		flags: func(app *appEnv, fl *flag.FlagSet) {
+			app.journalFlag(fl)
+			fl.BoolVar(&app.all, "all", false, "verify every run in the journal instead of the last one with checksums")
+		},
+		run: (*appEnv).Verify,
+	},
+	{
+		name:    "stats",
+		summary: "count files and bytes by kind and month",
+		flags: func(app *appEnv, fl *flag.FlagSet) {
+			fl.StringVar(&app.dir, "dir", ".", "directory to read")
+			app.kindsFlag(fl)
+			app.dateFlags(fl)
+			app.jobsFlag(fl)
+			choiceVar(fl, &app.format, "format", formatTable, "output `format`", statsFormats...)
+		},
+		run: (*appEnv).Stats,
+	},
+	{
+		name:    "prune",
+		summary: "remove empty folders",
+		flags: func(app *appEnv, fl *flag.FlagSet) {
+			fl.StringVar(&app.dir, "dir", ".", "directory to clean up")
+			fl.BoolVar(&app.dryRun, "dry-run", false, "just list the folders that would be removed")
+		},
+		run: (*appEnv).Prune,
+	},
+	{
+		name:    "flatten",
+		summary: "move files out of year folders back into the directory",
+		flags: func(app *appEnv, fl *flag.FlagSet) {
+			fl.StringVar(&app.dir, "dir", ".", "directory to flatten")
+			app.kindsFlag(fl)
+			app.jobsFlag(fl)
+			app.executeFlags(fl)
+			app.outputFlags(fl, true)
+			fl.BoolVar(&app.interactive, "interactive", false, "review the plan at a line prompt and choose which moves to make before moving")
+			fl.BoolVar(&app.confirm, "confirm", false, "summarize the plan and ask before moving")
+			fl.BoolVar(&app.pruneEmpty, "prune-empty", true, "remove empty folders in -dir after moving")
+		},
+		run: (*appEnv).Flatten,
+	},
+	{
+		name:     "reorganize",
+		multiDir: true,
+		summary:  "move files in year folders that belong elsewhere under the current options",
+		flags: func(app *appEnv, fl *flag.FlagSet) {
+			app.planFlags(fl)
+			app.executeFlags(fl)
+			app.outputFlags(fl, true)
+			fl.BoolVar(&app.interactive, "interactive", false, "review the plan at a line prompt and choose which moves to make before moving")
+			fl.BoolVar(&app.confirm, "confirm", false, "summarize the plan and ask before moving")
+			fl.BoolVar(&app.pruneEmpty, "prune-empty", true, "remove empty folders in -dir after moving")
+		},
+		run: (*appEnv).Reorganize,
+	},
+	{
+		name:    "doctor",
+		summary: "report misfiled items, unknown and empty folders, and name collisions",
+		flags: func(app *appEnv, fl *flag.FlagSet) {
+			app.planFlags(fl)
+			app.executeFlags(fl)
+			fl.BoolVar(&app.fix, "fix", false, "move misfiled items, rename collisions, and remove empty folders")
+		},
+		run: (*appEnv).Doctor,
+	},
+	{
+		name:    "install-agent",
+		args:    " [-- move options]",
+		nargs:   -1,
+		summary: "run move on a schedule with launchd",
+		flags: func(app *appEnv, fl *flag.FlagSet) {
+			fl.StringVar(&app.dir, "dir", ".", "directory to organize")
+			fl.DurationVar(&app.every, "every", time.Hour, "`interval` between runs")
+			app.settleFlag(fl, defaultSettle)
+		},
+		run: (*appEnv).InstallAgent,
+	},
+	{
+		name:    "uninstall-agent",
+		summary: "stop running move on a schedule",
+		flags:   func(app *appEnv, fl *flag.FlagSet) {},
+		run:     (*appEnv).UninstallAgent,
+	},
+	{
+		name:    "self-update",
+		summary: "replace scooter with the latest release from GitHub",
+		flags: func(app *appEnv, fl *flag.FlagSet) {
+			fl.BoolVar(&app.check, "check", false, "just report whether there is a newer release")
+			fl.BoolVar(&app.force, "force", false, "install the latest release even if it isn't newer, as for development builds")
+		},
+		run: (*appEnv).SelfUpdate,
+	},
+	{
+		name:    "watch",
+		summary: "organize new files as they appear once they stop changing",
+		flags: func(app *appEnv, fl *flag.FlagSet) {
+			app.planFlags(fl)
+			app.executeFlags(fl)
+			app.outputFlags(fl, true)
+			fl.DurationVar(&app.debounce, "debounce", 2*time.Second, "`delay` before organizing after a change")
+			app.settleFlag(fl, defaultSettle)
+			app.stateDirFlag(fl)
+		},
+		run: (*appEnv).Watch,
+	},
+	{
+		name:    "watch-state",
+		summary: "show or reset what watch remembers about a directory",
+		flags: func(app *appEnv, fl *flag.FlagSet) {
+			fl.StringVar(&app.dir, "dir", ".", "watched `directory`")
+			app.stateDirFlag(fl)
+			fl.BoolVar(&app.reset, "reset", false, "forget everything about -dir")
+			fl.BoolVar(&app.retry, "retry", false, "forget failures so that watch tries those files again")
+			choiceVar(fl, &app.format, "format", formatTable, "output `format`", formatTable, formatJSON)
+		},
+		run: (*appEnv).WatchState,
+	},
+}
+
+// lookupCommand returns the command named by args[0] and the rest of args.
+// If args[0] is not a command, it returns move and all of args.
+func lookupCommand(args []string) (command, []string) {
+	if len(args) > 0 {
+		for _, cmd := range commands {
+			if cmd.name == args[0] {
+				return cmd, args[1:]
+			}
+		}
+	}
+	return commands[0], args
+}
+
+func (app *appEnv) ParseArgs(cmd command, args []string) error {
+	app.start = time.Now()
+	fl := flag.NewFlagSet(AppName+" "+cmd.name, flag.ContinueOnError)
+	cmd.flags(app, fl)
+	app.commonFlags(fl)
 	fl.Usage = func() {
 		fmt.Fprintf(fl.Output(), `scooter - %s
 
@@ -53,11 +283,25 @@ Scoot files around by date and kind
 
 Usage:
 
-	scooter [options]
+	scooter [command] [options]
+
+Commands:
 
-Options:
 `, versioninfo.Version)
+		for _, c := range commands {
+			fmt.Fprintf(fl.Output(), "\t%-16s %s\n", c.name, c.summary)
+		}
+		fmt.Fprintf(fl.Output(), "\nOptions for scooter %s [options]%s:\n", cmd.name, cmd.args)
 		fl.PrintDefaults()
+		fmt.Fprintf(fl.Output(), `
+Exit status:
+
+	0  success
+	%d  usage or other error
+	%d  a file's date couldn't be looked up
+	%d  some moves failed
+	%d  nothing matched
+`, exitUsage, exitMetadata, exitPartial, exitNothing)
 	}
 	if err := fl.Parse(args); err != nil {
 		return err
@@ -65,149 +309,471 @@ Options:
 	if err := flagx.ParseEnv(fl, AppName); err != nil {
 		return err
 	}
+	if err := app.applyProfile(fl); err != nil {
+		fmt.Fprintln(fl.Output(), err)
+		return err
+	}
+	if cmd.pathArgs && fl.NArg() > 0 {
+		if err := app.expandPaths(fl.Args()); err != nil {
+			fmt.Fprintln(fl.Output(), err)
+			return err
+		}
+	}
+	if len(app.dirs) > 1 && !cmd.multiDir {
+		err := fmt.Errorf("%s takes only one -dir", cmd.name)
+		fmt.Fprintln(fl.Output(), err)
+		return err
+	}
+	if len(app.dirs) == 0 {
+		app.dirs = []string{app.dir}
+	}
+	if cmd.nargs >= 0 {
+		if err := flagx.MustHaveArgs(fl, cmd.nargs, cmd.nargs); err != nil {
+			return err
+		}
+	}
+	if app.kindsFile != "" {
+		kinds, err := LoadKinds(app.kindsFile)
+		if err != nil {
+			fmt.Fprintln(fl.Output(), err)
+			return err
+		}
+		app.opts.Kinds = kinds
+	}
+	if err := app.setupLogger(); err != nil {
+		fmt.Fprintln(fl.Output(), err)
+		return err
+	}
+	app.opts.Logger = app.Logger
+	if app.filesFrom != "" {
+		if app.filesFrom == "-" && (app.interactive || app.confirm) {
+			err := errors.New("can't read -files from standard input with -interactive or -confirm")
+			fmt.Fprintln(fl.Output(), err)
+			return err
+		}
+		files, err := readFileList(app.filesFrom, app.nul)
+		if err != nil {
+			return err
+		}
+		app.opts.Files = append(app.opts.Files, files...)
+	}
+	if !app.quiet && isTerminal(os.Stderr) {
+		bar := &progressBar{w: os.Stderr}
+		app.opts.Progress = bar.report
+	}
+	app.args = fl.Args()
 	return nil
 }
 
-type appEnv struct {
-	dir         string
-	excludeDirs bool
-	dryRun      bool
-	*log.Logger
+// commonFlags sets the options that every command has.
+func (app *appEnv) commonFlags(fl *flag.FlagSet) {
+	app.logFlags(fl)
+	fl.BoolVar(&app.quiet, "quiet", false, "don't show progress")
+	app.profileFlags(fl)
 }
 
-func (app *appEnv) Exec() (err error) {
-	entries, err := os.ReadDir(app.dir)
-	if err != nil {
+// planFlags sets the options for choosing which files move and where.
+func (app *appEnv) planFlags(fl *flag.FlagSet) {
+	app.dir = "."
+	fl.Func("dir", "`directory` to read (default \".\"; may be repeated for move, plan, and reorganize)", func(s string) error {
+		if len(app.dirs) == 0 {
+			app.dir = s
+		}
+		app.dirs = append(app.dirs, s)
+		return nil
+	})
+	fl.StringVar(&app.opts.Dest, "dest", "", "`directory` to move files into (default -dir)")
+	fl.Func("exclude", "gitignore style `pattern` for files to leave in place (may be repeated; added to "+IgnoreFile+")", func(s string) error {
+		if _, err := parseIgnore([]string{s}); err != nil {
+			return err
+		}
+		app.opts.Exclude = append(app.opts.Exclude, s)
+		return nil
+	})
+	fl.Func("route", "`kind=directory` to move files of kind into instead of -dest (may be repeated)", func(s string) error {
+		kind, dir, ok := strings.Cut(s, "=")
+		if !ok || kind == "" || dir == "" {
+			return errors.New("route must be kind=directory")
+		}
+		if app.opts.Routes == nil {
+			app.opts.Routes = make(map[string]string)
+		}
+		app.opts.Routes[kind] = dir
+		return nil
+	})
+	fl.Func("size-route", "`[kind]>size=directory` to move files of at least size, like >2GB=large, into directory, which is inside their usual root unless absolute (may be repeated)", func(s string) error {
+		sr, err := ParseSizeRoute(s)
+		if err != nil {
+			return err
+		}
+		app.opts.SizeRoutes = append(app.opts.SizeRoutes, sr)
+		return nil
+	})
+	choiceVar(fl, &app.opts.ICloud, "icloud", ICloudSkip, "how to handle files in iCloud Drive that aren't downloaded: leave them, download them, or move them without downloading", icloudModes...)
+	fl.BoolVar(&app.opts.ImportPhotos, "import-photos", false, "import images and videos into the Photos library and move them to the Trash instead of into folders (macOS only)")
+	fl.BoolVar(&app.opts.ExcludeDirs, "exclude-dirs", false, "don't move directories, except packages like .app bundles")
+	fl.Func("keep-in-place", fmt.Sprintf("comma separated gitignore style `patterns` for items never to move, or \"\" to move them (default %q)", strings.Join(defaultExcludes, ",")), func(s string) error {
+		app.opts.KeepInPlace = []string{}
+		for _, pattern := range strings.Split(s, ",") {
+			if pattern = strings.TrimSpace(pattern); pattern != "" {
+				app.opts.KeepInPlace = append(app.opts.KeepInPlace, pattern)
+			}
+		}
+		_, err := parseIgnore(app.opts.KeepInPlace)
 		return err
-	}
-	var paths []string
-	for _, entry := range entries {
-		name := entry.Name()
-		if entry.IsDir() || strings.HasPrefix(name, ".") {
-			continue
+	})
+	fl.Func("project-markers", "comma separated `names` of files that mark a directory as a project to leave in place, or \"\" to move projects (default \""+strings.Join(defaultProjectMarkers, ",")+"\")", func(s string) error {
+		app.opts.ProjectMarkers = []string{}
+		for _, name := range strings.Split(s, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				app.opts.ProjectMarkers = append(app.opts.ProjectMarkers, name)
+			}
 		}
-		path := filepath.Join(app.dir, name)
-		paths = append(paths, path)
-	}
-	type pair struct{ old, new string }
-	var pairs []pair
-	for _, path := range paths {
-		newname, err := buildName(path)
+		return nil
+	})
+	app.kindsFlag(fl)
+	app.dateFlags(fl)
+	choiceVar(fl, &app.opts.DirDate, "dir-date", "added", "`source` for the date of directories: their own date or that of the newest or oldest file inside", "added", "newest", "oldest")
+	fl.BoolVar(&app.opts.Recursive, "recursive", false, "move files inside of subdirectories instead of the directories themselves")
+	fl.IntVar(&app.opts.MaxDepth, "max-depth", 0, "maximum `depth` of subdirectories to scan with -recursive (0 for no limit)")
+	fl.StringVar(&app.filesFrom, "files", "", "`file` listing the items to move, one per line, or - for standard input")
+	fl.BoolVar(&app.nul, "0", false, "items in -files are separated by NUL characters, as from find -print0")
+	fl.StringVar(&app.opts.MDQuery, "mdquery", "", "Spotlight `query` choosing the items to move, like 'kMDItemFSSize > 100000000' (macOS only)")
+	fl.BoolVar(&app.opts.SkipQuarantined, "skip-quarantined", false, "leave downloads that haven't been opened since macOS quarantined them in place")
+	listVar(fl, &app.opts.OnlyKinds, "only-kind", "comma separated `kinds` to move, excluding all others (directories have no kind)")
+	listVar(fl, &app.opts.SkipKinds, "skip-kind", "comma separated `kinds` to leave in place")
+	fl.DurationVar(&app.opts.OlderThan, "older-than", 0, "only move files dated at least `duration` ago")
+	fl.DurationVar(&app.opts.NewerThan, "newer-than", 0, "only move files dated less than `duration` ago")
+	dateVar(fl, &app.opts.Since, "since", "only move files dated on or after `date` (YYYY-MM-DD)", 0)
+	dateVar(fl, &app.opts.Until, "until", "only move files dated on or before `date` (YYYY-MM-DD)", 1)
+	fl.Func("template", "Go text/template `layout` for destination folders using .Year, .Month, .MonthName, .Day, .Week, .WeekYear, .Quarter, .Kind, .Ext, .Name, .Base, .Source, .App, and .Date (default from -date-layout)", func(s string) error {
+		if _, err := parseTemplate(s); err != nil {
+			return err
+		}
+		app.opts.Template = s
+		return nil
+	})
+	fl.BoolVar(&app.opts.AirDrop, "airdrop", false, "move only files received with AirDrop, as soon as they arrive, into an AirDrop folder (also -profile airdrop)")
+	fl.BoolVar(&app.opts.ByApp, "by-app", false, "put files in a folder for the application they came from, like Safari, Mail, or AirDrop, when it is known")
+	choiceVar(fl, &app.opts.DateLayout, "date-layout", "month", "`layout` of date folders: 2025/05, 2025/05-May, 2025-05, 2025/W18, or 2025/Q2", dateLayoutNames...)
+	fl.Func("rename-template", "Go text/template `layout` for new file names using the same variables as -template, e.g. '{{.Date.Format \"2006-01-02\"}}_{{.Name}}' or '{{slug .Base}}.{{.Ext}}'", func(s string) error {
+		if _, err := parseTemplate(s); err != nil {
+			return err
+		}
+		app.opts.RenameTemplate = s
+		return nil
+	})
+	fl.Func("sanitize", "`replacement`, like _, for characters in destination names that Windows, exFAT, and SMB shares don't allow", func(s string) error {
+		if err := checkSanitize(s); err != nil {
+			return err
+		}
+		app.opts.Sanitize = s
+		return nil
+	})
+	fl.BoolVar(&app.opts.Dedupe, "dedupe", false, "leave files in place if an identical file is at or moving to their destination")
+	choiceVar(fl, &app.opts.Order, "order", OrderDestination, "`order` to move files in, so the most important are moved first if a run is interrupted", orders...)
+	fl.IntVar(&app.opts.Limit, "limit", 0, "move at most `number` files per run, the first in -order (0 for no limit)")
+	app.jobsFlag(fl)
+	app.conflictFlag(fl)
+	app.checksumFlag(fl)
+}
+
+func (app *appEnv) kindsFlag(fl *flag.FlagSet) {
+	fl.StringVar(&app.kindsFile, "kinds", defaultConfigPath("kinds.toml"), "TOML `file` mapping kinds to extensions")
+	choiceVar(fl, &app.opts.Classify, "classify", ClassifyExt, "`method` for classifying files by kind", classifiers...)
+	fl.BoolVar(&app.opts.Sniff, "sniff", false, "classify files without a known extension by their contents")
+}
+
+// dateFlags sets the options for dating files.
+func (app *appEnv) dateFlags(fl *flag.FlagSet) {
+	fl.Func("date-source", "comma separated `list` of date sources to try in order: added, birthtime, filename, mtime (default \""+strings.Join(defaultDateSources, ",")+"\")", func(s string) error {
+		sources, err := parseDateSources(s)
 		if err != nil {
 			return err
 		}
-		pairs = append(pairs, pair{path, filepath.Join(app.dir, newname)})
+		app.opts.DateSources = sources
+		return nil
+	})
+	choiceVar(fl, &app.opts.OnDateError, "on-date-error", DateErrorMtime, "`action` for files that -date-source has no date for: date them by modification time, leave them in place, or fail", dateErrorModes...)
+	choiceVar(fl, &app.opts.PhotoDate, "photo-date", "added", "`source` for the date of images", "added", "exif")
+	choiceVar(fl, &app.opts.DocDate, "doc-date", "added", "`source` for the date of PDF and Office documents", "added", "metadata")
+}
+
+func (app *appEnv) jobsFlag(fl *flag.FlagSet) {
+	fl.IntVar(&app.opts.Jobs, "jobs", runtime.NumCPU(), "`number` of files to look up at once")
+}
+
+// executeFlags sets the options for carrying out moves.
+func (app *appEnv) executeFlags(fl *flag.FlagSet) {
+	if fl.Lookup("on-conflict") == nil {
+		app.conflictFlag(fl)
 	}
+	app.checksumFlag(fl)
+	fl.BoolVar(&app.opts.Copy, "copy", false, "copy files instead of moving them, cloning when possible")
+	flagx.BoolFunc(fl, "dedupe-trash", "like -dedupe, but move the duplicates to the Trash", func() error {
+		app.opts.Dedupe = true
+		app.opts.DedupeTrash = true
+		return nil
+	})
+	durationVar(fl, &app.opts.LeaveSymlink, "leave-symlink", 0, "leave a link to each moved file in its old place and remove links older than `duration`, like 7d (not on Windows)")
+	fl.BoolVar(&app.opts.TagKinds, "tag-kinds", false, "give moved files a Finder tag named after their kind")
+	choiceVar(fl, &app.opts.TagColor, "tag-color", "none", "`color` of the tags from -tag-kinds", tagColorNames...)
+	fl.Func("dir-mode", "octal `mode` of the folders created for files, before the umask (default 755)", func(s string) error {
+		mode, err := strconv.ParseUint(s, 8, 32)
+		if err != nil || mode == 0 || mode > 0o777 {
+			return fmt.Errorf("bad folder mode %q", s)
+		}
+		app.opts.DirMode = fs.FileMode(mode)
+		return nil
+	})
+	fl.DurationVar(&app.opts.Throttle, "throttle", 0, "pause for `duration`, like 50ms, between moves to go easy on slow disks and sync clients")
+	fl.BoolVar(&app.opts.ClearQuarantine, "clear-quarantine", false, "remove the quarantine macOS puts on downloaded archives and installers after moving them")
+	fl.BoolVar(&app.opts.SyncSafe, "sync-safe", false, "in Dropbox, Syncthing, and other sync folders, wait for files to finish syncing and pause between moves")
+	fl.BoolVar(&app.opts.SkipOpen, "skip-open", false, "leave files that another program has open in place")
+	fl.BoolVar(&app.opts.Localize, "localize", false, "add translated names to kind folders for the Finder")
+	fl.BoolVar(&app.notify, "notify", false, "post a notification summarizing the moves")
+	fl.BoolVar(&app.reveal, "reveal", false, "after moving, show the moved file or the newest date folder in the Finder")
+	fl.StringVar(&app.postRunCmd, "post-run-cmd", "", "shell `command` to run after moving, with the path of a CSV file listing the moves as its last argument")
+	fl.StringVar(&app.webhook, "webhook", "", "`URL` to POST a JSON summary of the run to after moving")
+	fl.StringVar(&app.summaryFile, "summary-file", "", "`file` to write a JSON summary of the run to (- for standard output)")
+	fl.BoolVar(&app.wait, "wait", false, "wait for another run organizing the directory to finish instead of exiting")
+	fl.BoolVar(&app.opts.KeepGoing, "keep-going", false, "continue after a move fails and exit with status 3")
+	fl.StringVar(&app.report, "report", "", "CSV or JSON `file` to write failed moves to with -keep-going")
+	app.journalFlag(fl)
+}
+
+// defaultSettle is how long files must go unchanged
+// before watch and scheduled runs move them.
+const defaultSettle = 30 * time.Second
+
+func (app *appEnv) settleFlag(fl *flag.FlagSet, value time.Duration) {
+	fl.DurationVar(&app.opts.Settle, "settle", value, "only move files that have gone unchanged for `duration`, since they may still be being written")
+}
 
-	if !app.excludeDirs {
-		var dirpaths []string
-		for _, entry := range entries {
-			name := entry.Name()
-			if !entry.IsDir() || strings.HasPrefix(name, ".") ||
-				(len(name) == 4 && strings.HasPrefix(name, "20")) {
-				continue
+func (app *appEnv) checksumFlag(fl *flag.FlagSet) {
+	if fl.Lookup("checksum") == nil {
+		choiceVar(fl, &app.opts.Checksum, "checksum", "none", "`hash` of each file to record in the plan and journal for verify", checksumAlgorithms...)
+	}
+}
+
+func (app *appEnv) conflictFlag(fl *flag.FlagSet) {
+	choiceVar(fl, &app.opts.OnConflict, "on-conflict", ConflictRename, "`strategy` for destinations that already exist", conflictStrategies...)
+}
+
+func (app *appEnv) journalFlag(fl *flag.FlagSet) {
+	fl.StringVar(&app.opts.Journal, "journal", defaultJournalPath(), "CSV `file` recording moves for undo (empty to disable)")
+}
+
+func (app *appEnv) stateDirFlag(fl *flag.FlagSet) {
+	fl.StringVar(&app.stateDir, "state-dir", defaultStateDir(), "`directory` where watch remembers processed and failed files (empty to disable)")
+}
+
+// outputFlags sets the options for printing plans.
+func (app *appEnv) outputFlags(fl *flag.FlagSet, dryRun bool) {
+	if dryRun {
+		fl.BoolVar(&app.dryRun, "dry-run", false, "just output file locations without moving")
+	}
+	choiceVar(fl, &app.format, "format", formatCSV, "output `format` for plans", planFormats...)
+	fl.BoolVar(&app.noColor, "no-color", false, "don't color tables written to a terminal (also set by NO_COLOR)")
+}
+
+type appEnv struct {
+	dir         string
+	dirs        []string // every -dir, for commands that take several
+	opts        Options
+	kindsFile   string
+	configFile  string
+	profile     string
+	dryRun      bool
+	filesFrom   string
+	nul         bool
+	interactive bool
+	confirm     bool
+	pruneEmpty  bool
+	rollback    bool
+	wait        bool
+	notify      bool
+	reveal      bool
+	postRunCmd  string
+	webhook     string
+	summaryFile string
+	diff        string
+	noColor     bool
+	check       bool
+	force       bool
+	stateDir    string
+	reset       bool
+	retry       bool
+	skipped     map[string]bool // moves skipped by Execute, by old path
+	moved       map[string]bool // moves made by Execute, by old path
+	start       time.Time
+	fix         bool
+	all         bool
+	quiet       bool
+	format      string
+	debounce    time.Duration
+	every       time.Duration
+	report      string
+	args        []string
+	logLevel    string
+	logFormat   string
+	logFile     string
+	verbose     bool
+	*slog.Logger
+}
+
+func (app *appEnv) Exec(ctx context.Context) error {
+	return app.exec(ctx, Plan)
+}
+
+// Flatten moves the contents of the year folders in app.dir back into it.
+func (app *appEnv) Flatten(ctx context.Context) error {
+	return app.exec(ctx, PlanFlatten)
+}
+
+// Reorganize moves the contents of the year folders in app.dir
+// to match the current template and kinds.
+func (app *appEnv) Reorganize(ctx context.Context) error {
+	return app.exec(ctx, PlanReorganize)
+}
+
+// exec carries out the moves returned by plan for app.dirs as one run.
+func (app *appEnv) exec(ctx context.Context, plan func(dir string, opts Options) ([]Move, error)) (err error) {
+	if !app.dryRun {
+		for _, dir := range app.dirs {
+			var unlock func() error
+			if unlock, err = lockDir(ctx, dir, app.wait); err != nil {
+				return err
 			}
-			path := filepath.Join(app.dir, name)
-			dirpaths = append(dirpaths, path)
+			defer func() {
+				err = errors.Join(err, unlock())
+			}()
 		}
-		for _, dirpath := range dirpaths {
-			date, err := getDateAdded(dirpath)
+	}
+	if app.opts.LeaveSymlink > 0 && !app.dryRun {
+		for _, dir := range app.dirs {
+			removed, err := CleanupLinks(dir, app.opts.LeaveSymlink, false)
+			for _, path := range removed {
+				app.Info("removed old link", "path", path)
+			}
 			if err != nil {
 				return err
 			}
-			name := filepath.Base(dirpath)
-			newname := date.Format("2006/01/") + name
-			newpath := filepath.Join(app.dir, newname)
-			pairs = append(pairs, pair{dirpath, newpath})
 		}
 	}
-
-	// Sort by destination
-	slices.SortFunc(pairs, func(a, b pair) int {
-		return cmp.Compare(a.new, b.new)
-	})
-
+	moves, err := planDirs(app.dirs, app.opts, plan)
+	if merr := (*MetadataError)(nil); errors.As(err, &merr) {
+		return exitcode.Set(err, exitMetadata)
+	}
+	if err != nil {
+		return err
+	}
+	if app.diff != "" {
+		return app.printDiff(moves)
+	}
 	if app.dryRun {
-		w := csv.NewWriter(os.Stdout)
-		_ = w.Write([]string{"old", "new"})
-		for _, p := range pairs {
-			_ = w.Write([]string{p.old, p.new})
+		if err = app.printPlan(moves); err != nil {
+			return err
+		}
+		if err = app.printFileHooks(moves); err != nil || len(moves) > 0 {
+			return err
+		}
+		return errNothing
+	}
+	nothing := len(moves) == 0
+	if app.interactive {
+		if moves, err = app.review(moves, os.Stdin, os.Stdout); err != nil {
+			return err
+		}
+		if moves == nil {
+			return nil
 		}
-		w.Flush()
-		return w.Error()
 	}
-	for _, p := range pairs {
-		dir := filepath.Dir(p.new)
-		_ = os.MkdirAll(dir, 0o744)
-		if err = os.Rename(p.old, p.new); err != nil {
+	if app.confirm && len(moves) > 0 {
+		ok, err := app.confirmMoves(moves, os.Stdin, os.Stdout)
+		if err != nil || !ok {
 			return err
 		}
 	}
+	if err = app.execute(ctx, moves); err != nil {
+		return err
+	}
+	if app.pruneEmpty {
+		if err = app.Prune(ctx); err != nil {
+			return err
+		}
+	}
+	if nothing {
+		app.Info("nothing to move")
+		return errNothing
+	}
 	return nil
 }
 
-func buildName(path string) (string, error) {
-	dateAdded, err := getDateAdded(path)
-	if err != nil {
-		return "", err
-	}
-	kind := getKind(path)
-	name := filepath.Base(path)
-	return fmt.Sprintf("%d/%02d/%s/%s", dateAdded.Year(), dateAdded.Month(), kind, name), nil
+// execute calls Execute and reports any failed moves.
+// If app.notify is set, it also posts a notification summarizing the run,
+// and then it runs any hooks. If app.reveal is set and the run succeeded,
+// it shows the results in the file manager.
+func (app *appEnv) execute(ctx context.Context, moves []Move) error {
+	return app.executeRun(ctx, moves, "")
 }
 
-func getDateAdded(path string) (t time.Time, err error) {
+// executeRun is execute, journaling the moves as part of run if it is set,
+// as when resuming an interrupted run.
+func (app *appEnv) executeRun(ctx context.Context, moves []Move, run string) error {
+	app.skipped = make(map[string]bool)
+	app.moved = make(map[string]bool)
+	opts := app.opts
+	opts.Skipped = func(m Move, reason string) {
+		app.skipped[m.Old] = true
+	}
+	opts.Moved = func(m Move) {
+		app.moved[m.Old] = true
+	}
+	r, err := opts.runner("")
+	if err == nil {
+		err = r.executeAll(ctx, moves, run)
+	}
+	if app.summaryFile != "" {
+		if serr := app.writeSummary(moves, err); serr != nil {
+			app.Error("writing summary", "error", serr)
+		}
+	}
+	if len(moves) > 0 {
+		if app.notify {
+			app.notifyRun(moves, err)
+		}
+		app.runHooks(ctx, moves, err)
+		if app.reveal && err == nil {
+			app.revealRun(moves)
+		}
+	}
+	err = app.reportFailures(err)
 	var (
-		ok            bool
-		unixTimestamp float64
+		failed *FailedMovesError
+		fm     *FailedMove
 	)
-	s := strings.Clone(path)
-
-	// Was getting random memory corruption,
-	// so let's try just throwing in a pool
-	objc.WithAutoreleasePool(func() {
-		var dateAdded foundation.Date
-		var err foundation.Error
-		url := foundation.NewURLFileURLWithPath(s)
-		ok = url.GetResourceValueForKeyError(
-			unsafe.Pointer(&dateAdded),
-			foundation.URLAddedToDirectoryDateKey,
-			unsafe.Pointer(&err),
-		)
-		if !ok {
-			return
-		}
-		unixTimestamp = float64(dateAdded.TimeIntervalSince1970())
-	})
-	if !ok {
-		return time.Time{}, fmt.Errorf("could not read %q", path)
+	// Other errors, like a held lock or a journal that can't be written,
+	// aren't partial runs
+	if errors.As(err, &failed) || errors.As(err, &fm) && len(app.moved) > 0 {
+		return exitcode.Set(err, exitPartial)
 	}
-
-	seconds := math.Floor(unixTimestamp)
-	nanoseconds := (unixTimestamp - seconds) * 1e9
-
-	return time.Unix(int64(seconds), int64(nanoseconds)), nil
+	return err
 }
 
-func getKind(name string) string {
-	ext := path.Ext(name)
-	ext = strings.TrimPrefix(ext, ".")
-	ext = strings.ToLower(ext)
-	for _, s := range []string{
-		"archive: bz dmg gz tar tbz2 zip",
-		"audio: aac m4a mp3 wav",
-		"data: csv json xls xlsx",
-		"doc: doc docx pages pdf rtf rtfd txt",
-		"book: epub",
-		"image: avif bmp gif heic jpg jpeg  png svg tif webp",
-		"video: avi mp4 mpeg",
-		"web: css html ico js sass",
-	} {
-		kind, fields, _ := strings.Cut(s, ":")
-		exts := strings.Fields(fields)
-		if slices.Contains(exts, ext) {
-			return kind
+// reportFailures reports the failed moves in err, if any.
+func (app *appEnv) reportFailures(err error) error {
+	var failed *FailedMovesError
+	if !errors.As(err, &failed) {
+		return err
+	}
+	for _, f := range failed.Failures {
+		fmt.Fprintf(os.Stderr, "Failed: %q: %v\n", f.Old, f.Err)
+	}
+	if app.report != "" {
+		if reportErr := writeReport(app.report, failed.Failures); reportErr != nil {
+			err = errors.Join(err, reportErr)
 		}
 	}
-	return "misc"
+	return err
 }
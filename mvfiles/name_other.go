@@ -0,0 +1,8 @@
+//go:build !windows
+
+package mvfiles
+
+// safeName returns name, which is valid on this platform as it is.
+func safeName(name string) string {
+	return name
+}
@@ -0,0 +1,19 @@
+package mvfiles
+
+import "strings"
+
+// safeName returns name with an underscore added
+// if it is reserved by Windows, like "con" or "aux.txt".
+func safeName(name string) string {
+	base, ext := splitExt(name)
+	for _, reserved := range reservedNames {
+		if strings.EqualFold(base, reserved) {
+			return base + "_" + ext
+		}
+	}
+	// Windows drops trailing dots and spaces
+	if trimmed := strings.TrimRight(name, ". "); trimmed != name {
+		return trimmed + "_"
+	}
+	return name
+}
@@ -0,0 +1,23 @@
+package mvfiles
+
+import "testing"
+
+func TestSafeName(t *testing.T) {
+	for name, want := range map[string]string{
+		"report.pdf":  "report.pdf",
+		"con":         "con_",
+		"CON":         "CON_",
+		"aux.txt":     "aux_.txt",
+		"Com1.tar.gz": "Com1_.tar.gz",
+		"lpt9.log":    "lpt9_.log",
+		"console.txt": "console.txt",
+		"notes.":      "notes_",
+		"notes ":      "notes_",
+		"notes. . ":   "notes_",
+		".hidden":     ".hidden",
+	} {
+		if got := safeName(name); got != want {
+			t.Errorf("safeName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
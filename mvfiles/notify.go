@@ -0,0 +1,49 @@
+package mvfiles
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// notifyRun posts a notification summarizing the execution of moves,
+// like "Moved 58 files into 2025/05".
+func (app *appEnv) notifyRun(moves []Move, err error) {
+	verb := "Moved"
+	if app.opts.Copy {
+		verb = "Copied"
+	}
+	var failed *FailedMovesError
+	errors.As(err, &failed)
+	msg := fmt.Sprintf("%s %s into %s", verb, plural(len(app.moved), "file"), commonFolder(app.dir, moves))
+	if failed != nil {
+		msg += fmt.Sprintf("; %d failed", len(failed.Failures))
+	} else if err != nil {
+		msg = "Stopped: " + err.Error()
+	}
+	if err := notify("Scooter", msg); err != nil {
+		app.Warn("could not notify", "error", err)
+	}
+}
+
+// commonFolder returns the folder holding all of the destinations of moves
+// relative to dir, or a count of the folders if they are spread out.
+func commonFolder(dir string, moves []Move) string {
+	common := ""
+	folders := make(map[string]bool)
+	for i, m := range moves {
+		folder := filepath.Dir(m.New)
+		folders[folder] = true
+		if i == 0 {
+			common = folder
+		}
+		for common != "." && common != folder && !strings.HasPrefix(folder, common+string(filepath.Separator)) {
+			common = filepath.Dir(common)
+		}
+	}
+	if rel, err := filepath.Rel(dir, common); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return plural(len(folders), "folder")
+}
@@ -0,0 +1,12 @@
+package mvfiles
+
+import (
+	"os/exec"
+	"strconv"
+)
+
+// notify posts a banner to Notification Center.
+func notify(title, msg string) error {
+	script := "display notification " + strconv.Quote(msg) + " with title " + strconv.Quote(title)
+	return exec.Command("osascript", "-e", script).Run()
+}
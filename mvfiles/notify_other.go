@@ -0,0 +1,10 @@
+//go:build !darwin
+
+package mvfiles
+
+import "os/exec"
+
+// notify posts a desktop notification with notify-send, if it is installed.
+func notify(title, msg string) error {
+	return exec.Command("notify-send", "--app-name=scooter", title, msg).Run()
+}
@@ -0,0 +1,29 @@
+//go:build cgo
+
+package mvfiles
+
+// #include <stdlib.h>
+import "C"
+
+import "unsafe"
+
+// withOutParams calls f with n Objective-C out parameters, like the
+// NSError ** of methods that report errors, and returns the objects
+// written to them, which are nil if nothing was written.
+// The out parameters are in C memory, since Objective-C would otherwise
+// store pointers into Go memory without the garbage collector knowing,
+// and they must not be used after f returns.
+func withOutParams(n int, f func(out []unsafe.Pointer)) []unsafe.Pointer {
+	block := C.calloc(C.size_t(n), C.size_t(unsafe.Sizeof(unsafe.Pointer(nil))))
+	if block == nil {
+		panic("out of memory")
+	}
+	defer C.free(block)
+	slots := unsafe.Slice((*unsafe.Pointer)(block), n)
+	out := make([]unsafe.Pointer, n)
+	for i := range slots {
+		out[i] = unsafe.Pointer(&slots[i])
+	}
+	f(out)
+	return append([]unsafe.Pointer(nil), slots...)
+}
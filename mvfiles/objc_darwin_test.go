@@ -0,0 +1,25 @@
+//go:build cgo
+
+package mvfiles
+
+import (
+	"runtime"
+	"testing"
+	"unsafe"
+)
+
+func TestWithOutParams(t *testing.T) {
+	vals := withOutParams(3, func(out []unsafe.Pointer) {
+		for i, p := range out {
+			if *(*unsafe.Pointer)(p) != nil {
+				t.Errorf("out parameter %d starts as %p, want nil", i, *(*unsafe.Pointer)(p))
+			}
+		}
+		// Stand in for an object with the address of another out parameter
+		*(*unsafe.Pointer)(out[2]) = out[0]
+		runtime.GC()
+	})
+	if len(vals) != 3 || vals[0] != nil || vals[1] != nil || vals[2] == nil {
+		t.Errorf("withOutParams = %v, want only the last set", vals)
+	}
+}
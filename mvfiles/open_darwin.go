@@ -0,0 +1,36 @@
+package mvfiles
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// isOpen reports whether another process has the file at path,
+// or any file inside of the directory at path, open.
+func isOpen(path string) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	args := []string{"-t", "-w", "--", path}
+	if info.IsDir() {
+		args = []string{"-t", "-w", "+D", path}
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command("lsof", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	// lsof exits with status 1 when nothing is open
+	var exit *exec.ExitError
+	if errors.As(err, &exit) && exit.ExitCode() == 1 && len(out) == 0 {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("lsof: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return len(bytes.TrimSpace(out)) > 0, nil
+}
@@ -0,0 +1,47 @@
+package mvfiles
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// isOpen reports whether another process has the file at path,
+// or any file inside of the directory at path, open.
+// Processes of other users can't be checked without privileges.
+func isOpen(path string) (bool, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false, err
+	}
+	if _, err = os.Lstat(abs); err != nil {
+		return false, err
+	}
+	procs, err := os.ReadDir("/proc")
+	if err != nil {
+		return false, err
+	}
+	self := strconv.Itoa(os.Getpid())
+	for _, proc := range procs {
+		if _, err := strconv.Atoi(proc.Name()); err != nil || proc.Name() == self {
+			continue
+		}
+		fdDir := filepath.Join("/proc", proc.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			// Gone, or not ours to look at
+			continue
+		}
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil {
+				continue
+			}
+			if target == abs || strings.HasPrefix(target, abs+"/") {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
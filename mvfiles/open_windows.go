@@ -0,0 +1,38 @@
+package mvfiles
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"syscall"
+)
+
+// isOpen reports whether another process has the file at path,
+// or any file inside of the directory at path, open,
+// by trying to open the files without sharing.
+func isOpen(path string) (bool, error) {
+	open := false
+	err := filepath.WalkDir(path, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		p, err := syscall.UTF16PtrFromString(name)
+		if err != nil {
+			return err
+		}
+		h, err := syscall.CreateFile(p, syscall.GENERIC_READ, 0, nil,
+			syscall.OPEN_EXISTING, syscall.FILE_ATTRIBUTE_NORMAL, 0)
+		if errors.Is(err, errorSharingViolation) {
+			open = true
+			return filepath.SkipAll
+		}
+		if err != nil {
+			return &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+		return syscall.CloseHandle(h)
+	})
+	return open, err
+}
@@ -0,0 +1,42 @@
+package mvfiles
+
+import (
+	"cmp"
+	"path/filepath"
+	"slices"
+)
+
+// Orders for Options.Order
+const (
+	OrderDestination = "destination" // by destination path
+	OrderOldest      = "oldest"      // oldest date first
+	OrderNewest      = "newest"      // newest date first
+	OrderName        = "name"        // by file name
+	OrderSize        = "size"        // biggest first
+)
+
+var orders = []string{OrderDestination, OrderOldest, OrderNewest, OrderName, OrderSize}
+
+// arrange sorts moves by r.Order, breaking ties by destination,
+// and returns the first r.Limit of them.
+func (r *runner) arrange(moves []Move) []Move {
+	slices.SortStableFunc(moves, func(a, b Move) int {
+		var c int
+		switch r.Order {
+		case OrderOldest:
+			c = a.Date.Compare(b.Date)
+		case OrderNewest:
+			c = b.Date.Compare(a.Date)
+		case OrderName:
+			c = cmp.Compare(filepath.Base(a.Old), filepath.Base(b.Old))
+		case OrderSize:
+			c = cmp.Compare(b.Size, a.Size)
+		}
+		return cmp.Or(c, cmp.Compare(a.New, b.New))
+	})
+	if r.Limit > 0 && len(moves) > r.Limit {
+		r.Logger.Info("limiting moves", "planned", len(moves), "limit", r.Limit)
+		moves = moves[:r.Limit]
+	}
+	return moves
+}
@@ -0,0 +1,132 @@
+package mvfiles
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Extended attributes recording where a moved file came from,
+// so it can be found and put back without the journal.
+const (
+	originPathXattr = xattrPrefix + "org.scooter.original-path"
+	originRunXattr  = xattrPrefix + "org.scooter.run"
+	originTimeXattr = xattrPrefix + "org.scooter.moved-at"
+)
+
+// setOrigin records on the item at path that it was moved from oldpath by run.
+func setOrigin(path, oldpath, run string) error {
+	oldpath, err := filepath.Abs(oldpath)
+	if err != nil {
+		return err
+	}
+	return errors.Join(
+		setXattr(path, originPathXattr, []byte(oldpath)),
+		setXattr(path, originRunXattr, []byte(run)),
+		setXattr(path, originTimeXattr, []byte(time.Now().Format(time.RFC3339))),
+	)
+}
+
+// origin is where an item found by findOrigins came from.
+type origin struct {
+	Path, Old, Run, Time string
+}
+
+// findOrigins returns the items in dir and its subdirectories
+// with a recorded origin. It does not look inside of those items.
+func findOrigins(dir string) ([]origin, error) {
+	var origins []origin
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		old, err := getXattr(path, originPathXattr)
+		if err != nil || len(old) == 0 {
+			return nil
+		}
+		run, _ := getXattr(path, originRunXattr)
+		t, _ := getXattr(path, originTimeXattr)
+		origins = append(origins, origin{path, string(old), string(run), string(t)})
+		if d.IsDir() {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	return origins, err
+}
+
+// undoFromOrigins moves the items in app.dir from the latest run
+// recorded on them back to where they came from.
+func (app *appEnv) undoFromOrigins(ctx context.Context) error {
+	origins, err := findOrigins(app.dir)
+	if err != nil {
+		return err
+	}
+	origins = slices.DeleteFunc(origins, func(o origin) bool {
+		return o.Path == o.Old
+	})
+	if len(origins) == 0 {
+		return errors.New("nothing to undo")
+	}
+	// Run IDs sort by time
+	run := slices.MaxFunc(origins, func(a, b origin) int {
+		return strings.Compare(a.Run, b.Run)
+	}).Run
+	var moves []Move
+	for _, o := range origins {
+		if o.Run == run {
+			moves = append(moves, Move{Old: o.Path, New: o.Old})
+		}
+	}
+	app.Info("undoing run", "run", run)
+	if app.dryRun {
+		return app.printPlan(moves)
+	}
+	for _, m := range moves {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if _, err = os.Lstat(m.New); err == nil {
+			return fmt.Errorf("cannot restore %q: %w", m.New, fs.ErrExist)
+		}
+		if err = os.MkdirAll(filepath.Dir(m.New), defaultDirMode); err != nil {
+			return err
+		}
+		if err = moveFile(m.Old, m.New); err != nil {
+			return err
+		}
+		app.Info("restored", "old", m.Old, "new", m.New)
+	}
+	return nil
+}
+
+// whereFromOrigins is like where but searches the items in dir.
+func whereFromOrigins(dir, pattern string) ([]whereResult, error) {
+	pattern = strings.ToLower(pattern)
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	origins, err := findOrigins(dir)
+	if err != nil {
+		return nil, err
+	}
+	var results []whereResult
+	for _, o := range origins {
+		if ok, _ := filepath.Match(pattern, strings.ToLower(filepath.Base(o.Old))); ok && o.Path != o.Old {
+			results = append(results, whereResult{Time: o.Time, Old: o.Old, Now: o.Path})
+		}
+	}
+	return results, nil
+}
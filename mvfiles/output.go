@@ -0,0 +1,180 @@
+package mvfiles
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Formats for -format
+const (
+	formatCSV    = "csv"
+	formatJSON   = "json"
+	formatNDJSON = "ndjson"
+	formatTable  = "table"
+)
+
+var (
+	planFormats  = []string{formatCSV, formatJSON, formatNDJSON, formatTable}
+	statsFormats = []string{formatCSV, formatJSON, formatTable}
+)
+
+func writePlan(w io.Writer, format string, moves []Move) error {
+	switch format {
+	case formatJSON:
+		if moves == nil {
+			moves = []Move{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(moves)
+	case formatNDJSON:
+		enc := json.NewEncoder(w)
+		for _, m := range moves {
+			if err := enc.Encode(m); err != nil {
+				return err
+			}
+		}
+		return nil
+	case formatTable:
+		return writeTable(w, moves, false)
+	}
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"old", "new", "kind", "date", "size", "duplicate", "sanitized", "checksum"})
+	for _, m := range moves {
+		dup, sanitized := "", ""
+		if m.Duplicate {
+			dup = "true"
+		}
+		if m.Sanitized {
+			sanitized = "true"
+		}
+		_ = cw.Write([]string{
+			m.Old, m.New, m.Kind, formatDate(m.Date, time.RFC3339),
+			strconv.FormatInt(m.Size, 10), dup, sanitized, m.Checksum,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeTable writes moves as a table, with the kinds in color if color is set.
+func writeTable(w io.Writer, moves []Move, color bool) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	header := "KIND"
+	if color {
+		// Pad the header as much as the colored kinds
+		header = colorize(kindColor(""), header)
+	}
+	fmt.Fprintf(tw, "SOURCE\tDESTINATION\t%s\tDATE\tSIZE\n", header)
+	for _, m := range moves {
+		dest := m.New
+		if m.Duplicate {
+			dest = "duplicate of " + dest
+		}
+		kind := m.Kind
+		if color {
+			kind = colorize(kindColor(kind), kind)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			m.Old, dest, kind, formatDate(m.Date, time.DateOnly), formatSize(m.Size))
+	}
+	return tw.Flush()
+}
+
+func writeStats(w io.Writer, format string, stats *Stats) error {
+	switch format {
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(stats)
+	case formatTable:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "KIND\tFILES\tSIZE")
+		for _, s := range stats.Kinds {
+			fmt.Fprintf(tw, "%s\t%d\t%s\n", s.Name, s.Files, formatSize(s.Bytes))
+		}
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "MONTH\tFILES\tSIZE")
+		for _, s := range stats.Months {
+			fmt.Fprintf(tw, "%s\t%d\t%s\n", cmp.Or(s.Name, "undated"), s.Files, formatSize(s.Bytes))
+		}
+		return tw.Flush()
+	}
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"group", "name", "files", "bytes"})
+	for _, group := range []struct {
+		name  string
+		stats []Stat
+	}{{"kind", stats.Kinds}, {"month", stats.Months}} {
+		for _, s := range group.stats {
+			_ = cw.Write([]string{
+				group.name, s.Name, strconv.Itoa(s.Files), strconv.FormatInt(s.Bytes, 10),
+			})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeReport writes failures to the file name as JSON
+// if it ends in .json, or else as CSV.
+func writeReport(name string, failures []FailedMove) (err error) {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, f.Close())
+	}()
+	if strings.EqualFold(filepath.Ext(name), ".json") {
+		type failure struct {
+			Move
+			Error string `json:"error"`
+		}
+		list := make([]failure, 0, len(failures))
+		for _, fm := range failures {
+			list = append(list, failure{fm.Move, fm.Err.Error()})
+		}
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		return enc.Encode(list)
+	}
+	cw := csv.NewWriter(f)
+	_ = cw.Write([]string{"old", "new", "error"})
+	for _, fm := range failures {
+		_ = cw.Write([]string{fm.Old, fm.New, fm.Err.Error()})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatDate(t time.Time, layout string) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(layout)
+}
+
+// formatSize returns a human readable size, like "1.8 GB".
+func formatSize(size int64) string {
+	const unit = 1000
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "kMGTPE"[exp])
+}
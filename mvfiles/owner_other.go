@@ -0,0 +1,8 @@
+//go:build !unix
+
+package mvfiles
+
+// chownLike does nothing, since ownership isn't set on this platform.
+func chownLike(name, like string) error {
+	return nil
+}
@@ -0,0 +1,25 @@
+//go:build unix
+
+package mvfiles
+
+import (
+	"os"
+	"syscall"
+)
+
+// chownLike gives name the owner and group of like if running as root,
+// so that folders made with sudo don't end up owned by root.
+func chownLike(name, like string) error {
+	if os.Geteuid() != 0 {
+		return nil
+	}
+	info, err := os.Stat(like)
+	if err != nil {
+		return err
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	return os.Lchown(name, int(st.Uid), int(st.Gid))
+}
@@ -0,0 +1,31 @@
+package mvfiles
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// packageKind is the kind of packages whose extension has no other kind.
+const packageKind = "package"
+
+// packageExts are the extensions of common packages, folders that
+// the Finder shows as a single file.
+var packageExts = map[string]bool{
+	"app": true, "bundle": true, "framework": true, "kext": true, "plugin": true,
+	"key": true, "numbers": true, "pages": true, "rtfd": true,
+	"photoslibrary": true, "musiclibrary": true, "fcpbundle": true,
+	"logicx": true, "band": true, "sparsebundle": true,
+	"xcodeproj": true, "xcworkspace": true, "playground": true,
+	"scptd": true, "xcarchive": true,
+}
+
+// isPackage reports whether the folder at path is a package
+// that should be moved as a single item, either because of
+// its extension or because the system says it is one.
+func isPackage(path string) bool {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	if packageExts[ext] {
+		return true
+	}
+	return isFilePackage(path)
+}
@@ -0,0 +1,20 @@
+//go:build cgo
+
+package mvfiles
+
+import (
+	"strings"
+
+	"github.com/progrium/darwinkit/macos/appkit"
+	"github.com/progrium/darwinkit/objc"
+)
+
+// isFilePackage reports whether the Finder treats the folder at path
+// as a package, based on its bundle bit or its registered type.
+func isFilePackage(path string) (ok bool) {
+	s := strings.Clone(path)
+	objc.WithAutoreleasePool(func() {
+		ok = appkit.Workspace_SharedWorkspace().IsFilePackageAtPath(s)
+	})
+	return ok
+}
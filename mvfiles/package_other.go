@@ -0,0 +1,7 @@
+//go:build !darwin || !cgo
+
+package mvfiles
+
+func isFilePackage(path string) bool {
+	return false
+}
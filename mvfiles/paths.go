@@ -0,0 +1,56 @@
+package mvfiles
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// expandPaths sets app.dirs and app.opts.Files from the path arguments in args,
+// after expanding a leading ~ and glob patterns, for shells that don't.
+// Directories named without a pattern are organized like -dir.
+// Other items are the only ones moved, and unless -dir is set,
+// they are organized within the directories they are in.
+func (app *appEnv) expandPaths(args []string) error {
+	var dirs, files []string
+	for _, arg := range args {
+		arg = expandHome(arg)
+		if !strings.ContainsAny(arg, "*?[") {
+			info, err := os.Stat(arg)
+			if err != nil {
+				return err
+			}
+			if info.IsDir() && !isPackage(arg) {
+				dirs = append(dirs, arg)
+			} else {
+				files = append(files, arg)
+			}
+			continue
+		}
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return fmt.Errorf("bad pattern %q: %w", arg, err)
+		}
+		if len(matches) == 0 {
+			return fmt.Errorf("no matches for %q", arg)
+		}
+		files = append(files, matches...)
+	}
+	if len(dirs) > 0 && len(files) > 0 {
+		return errors.New("give either directories to organize or items to move, not both")
+	}
+	app.dirs = append(app.dirs, dirs...)
+	app.opts.Files = append(app.opts.Files, files...)
+	if len(app.dirs) == 0 {
+		for _, file := range files {
+			if dir := filepath.Dir(file); !slices.Contains(app.dirs, dir) {
+				app.dirs = append(app.dirs, dir)
+			}
+		}
+	}
+	app.dir = app.dirs[0]
+	return nil
+}
@@ -0,0 +1,40 @@
+package mvfiles
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// PhotosPrefix starts the destinations of files imported into the Photos library
+// instead of moved, like "photos:IMG_0001.heic".
+const PhotosPrefix = "photos:"
+
+// photosKinds are the kinds imported by Options.ImportPhotos.
+var photosKinds = []string{"image", "video"}
+
+// isPhotosImport reports whether the destination newpath is the Photos library.
+func isPhotosImport(newpath string) bool {
+	return strings.HasPrefix(newpath, PhotosPrefix)
+}
+
+// importPhoto imports m.Old into the Photos library and then moves it
+// to the Trash, unless r.Copy is set.
+func (r *runner) importPhoto(j *journal, m Move) error {
+	path, err := filepath.Abs(m.Old)
+	if err != nil {
+		return err
+	}
+	if err = importToPhotos(path); err != nil {
+		return err
+	}
+	r.Logger.Info("imported into Photos", "old", m.Old)
+	if r.Copy {
+		return nil
+	}
+	trashed, err := trashFile(m.Old)
+	if err != nil {
+		return err
+	}
+	r.Logger.Info(actionTrash, "old", m.Old, "new", trashed)
+	return j.record(actionTrash, m.Old, trashed)
+}
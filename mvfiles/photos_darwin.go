@@ -0,0 +1,21 @@
+package mvfiles
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// importToPhotos imports the file at the absolute path into the Photos library.
+func importToPhotos(path string) error {
+	script := `tell application "Photos" to import {POSIX file ` + strconv.Quote(path) + `} skip check duplicates false`
+	var stderr bytes.Buffer
+	cmd := exec.Command("osascript", "-e", script)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("importing into Photos: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
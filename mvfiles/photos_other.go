@@ -0,0 +1,9 @@
+//go:build !darwin
+
+package mvfiles
+
+import "errors"
+
+func importToPhotos(path string) error {
+	return errors.New("importing into Photos only works on macOS")
+}
@@ -0,0 +1,926 @@
+package mvfiles
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"text/template"
+	"time"
+)
+
+// Options control how Plan chooses destinations and how Execute moves files.
+// The zero value uses the same defaults as the command line.
+type Options struct {
+	// Dest is the root of the destinations. It defaults to the planned directory.
+	Dest string
+	// Template is a text/template layout for destination folders relative to Dest.
+	// It defaults to the template for DateLayout.
+	Template string
+	// ByApp puts files in a folder named for the application they came from,
+	// like Safari, Mail, or AirDrop, ahead of Template. Files from unknown
+	// applications go where Template alone puts them.
+	ByApp bool
+	// AirDrop limits the plan to files received with AirDrop and moves them
+	// into an AirDrop folder as with ByApp, however recently they arrived:
+	// OlderThan and NewerThan are ignored.
+	AirDrop bool
+	// DateLayout names the layout of the date folders in the default template:
+	// "month" (the default) for 2025/05, "month-name" for 2025/05-May,
+	// "year-month" for 2025-05, "week" for 2025/W18, or "quarter" for 2025/Q2.
+	DateLayout string
+	// Routes maps kinds to the roots of their destinations instead of Dest.
+	// They take precedence over the routes in Kinds.
+	Routes map[string]string
+	// SizeRoutes send big files to another root, like a "large" folder
+	// or an external volume. They take precedence over the size routes in Kinds.
+	SizeRoutes []SizeRoute
+	// RenameTemplate is a text/template for new file names, with the same
+	// variables as Template. Files keep their names if it is blank.
+	RenameTemplate string
+	// Kinds classifies files. It defaults to NewKinds().
+	Kinds *Kinds
+	// Classify is ClassifyExt (the default) to classify files by Kinds
+	// or ClassifyUTI to classify them by their Uniform Type Identifier,
+	// falling back to Kinds for unrecognized types.
+	Classify string
+	// Sniff classifies files without a known extension by their contents.
+	Sniff bool
+	// DateSources are tried in order to date a file: "added", "birthtime",
+	// "filename" for a date in the name, like invoice_20240517.pdf, and "mtime".
+	// It defaults to "added", "birthtime", and "mtime".
+	DateSources []string
+	// OnDateError is what to do with files that none of DateSources work for:
+	// "mtime" (the default) to date them by their modification time,
+	// "skip" to leave them in place, or "fail" to return a *MetadataError.
+	OnDateError string
+	// PhotoDate is "exif" to date images by when they were taken.
+	PhotoDate string
+	// DocDate is "metadata" to date documents by the creation date
+	// embedded in PDF and Office files.
+	DocDate string
+	// DirDate is "newest" or "oldest" to date directories by the newest
+	// or oldest file inside of them instead of by their own date ("added").
+	DirDate string
+	// Exclude is a list of gitignore style patterns for files to leave in place.
+	// They are added to the patterns in the directory's .scooterignore file
+	// and to KeepInPlace.
+	Exclude []string
+	// KeepInPlace is a list of gitignore style patterns for items that are never
+	// moved. It defaults to partial downloads, like *.crdownload, and files made
+	// by the system, like Thumbs.db. Use an empty slice to move them.
+	KeepInPlace []string
+	// ExcludeDirs leaves directories other than packages in place.
+	ExcludeDirs bool
+	// ProjectMarkers are names of files, like .git or go.mod, that mark
+	// a directory as a project to leave in place, along with its contents.
+	// It defaults to common markers. Use an empty slice to move projects.
+	ProjectMarkers []string
+	// Recursive plans the files inside of directories
+	// instead of the directories themselves.
+	Recursive bool
+	// MaxDepth limits how deep Recursive goes. Zero means no limit.
+	MaxDepth int
+	// MDQuery is a Spotlight query, like "kMDItemFSSize > 100000000", that chooses
+	// the items to plan instead of listing the directory. It only works on macOS.
+	MDQuery string
+	// Files lists the items to plan instead of listing the directory.
+	// Items outside of the directory are left out, as are items
+	// in its subdirectories unless Recursive is set.
+	Files []string
+	// SkipQuarantined leaves files in place that macOS quarantined
+	// when they were downloaded and that haven't been opened yet.
+	SkipQuarantined bool
+	// OnlyKinds limits the plan to files of these kinds.
+	OnlyKinds []string
+	// SkipKinds leaves files of these kinds out of the plan.
+	SkipKinds []string
+	// OlderThan and NewerThan limit the plan to files by the age of their date.
+	// Zero means no limit.
+	OlderThan, NewerThan time.Duration
+	// Settle leaves files in place that were modified less than Settle ago,
+	// since they may still be being written. Zero means no limit.
+	Settle time.Duration
+	// Since and Until limit the plan to files dated at or after Since
+	// and before Until. Zero means no limit.
+	Since, Until time.Time
+	// Order is the order of the planned moves, which Execute carries out
+	// in turn: OrderDestination (the default), OrderOldest, OrderNewest,
+	// OrderName, or OrderSize for the biggest first.
+	Order string
+	// Limit is the most moves to plan, keeping the first in Order,
+	// so that a run only moves part of a big directory. Zero means no limit.
+	Limit int
+	// OnConflict says what to do when a destination already exists:
+	// ConflictSkip, ConflictOverwrite, ConflictRename (the default),
+	// ConflictError, or ConflictTrash.
+	OnConflict string
+	// Dedupe finds files that are the same as a file already at their destination
+	// or a file planned to move there, and leaves them in place.
+	Dedupe bool
+	// DedupeTrash sends the duplicates found by Dedupe to the Trash.
+	DedupeTrash bool
+	// Throttle is how long Execute pauses between moves, to keep from
+	// saturating slow disks and network volumes or tripping sync clients.
+	Throttle time.Duration
+	// ClearQuarantine removes the quarantine macOS puts on downloads
+	// from archives and installers after moving them.
+	ClearQuarantine bool
+	// SyncSafe goes easy on folders kept in sync by Dropbox, Syncthing,
+	// and the like: moves are throttled unless Throttle is set,
+	// and files still syncing are waited for or left in place.
+	SyncSafe bool
+	// KeepGoing continues executing after a move fails.
+	KeepGoing bool
+	// Copy leaves the originals in place and puts copies in the destinations.
+	Copy bool
+	// DirMode is the permissions of the folders created for destinations,
+	// which the process umask is applied to. It defaults to 0o755.
+	// When running as root, the new folders are given the owner of the
+	// folder they are created in.
+	DirMode fs.FileMode
+	// Jobs is how many files to look up at once.
+	// It defaults to the number of CPUs.
+	Jobs int
+	// TagKinds gives moved files a Finder tag named after their kind.
+	TagKinds bool
+	// TagColor is the color of the tags added by TagKinds:
+	// "none" (the default), "gray", "green", "purple", "blue",
+	// "yellow", "red", or "orange".
+	TagColor string
+	// LeaveSymlink leaves a link at the old location of each moved item
+	// pointing to its new location. Links older than LeaveSymlink
+	// are removed by CleanupLinks. Zero means no links.
+	// Links are not supported on Windows.
+	LeaveSymlink time.Duration
+	// ICloud is how to handle files in iCloud Drive that aren't downloaded:
+	// ICloudSkip (the default) leaves them in place, ICloudMaterialize
+	// downloads them first, and ICloudMovePlaceholder moves them
+	// without downloading, dating them without reading their contents.
+	ICloud string
+	// ImportPhotos imports images and videos into the Photos library
+	// and moves them to the Trash instead of moving them into folders.
+	// Their destinations start with PhotosPrefix. It only works on macOS.
+	ImportPhotos bool
+	// SkipOpen leaves files that another process has open in place,
+	// so that files still being written aren't corrupted.
+	SkipOpen bool
+	// Localize adds translations of the names of the built-in kinds
+	// to the kind folders so the Finder shows them in the user's language.
+	Localize bool
+	// Sanitize is put in place of characters in destination names
+	// that Windows, exFAT, and SMB shares don't allow, like ":" and "?",
+	// and after trailing dots and spaces and reserved names like "con".
+	// Names are left as they are if it is blank.
+	Sanitize string
+	// Checksum is the hash recorded for each file in the plan and journal
+	// so that moved files can be verified later: "none" (the default)
+	// or "sha256".
+	Checksum string
+	// Journal is a file that records moves so they can be undone.
+	// Nothing is recorded if it is blank.
+	Journal string
+	// Progress is called after each file is looked up or moved
+	// with "scanning" or "moving", the number of files done and to do,
+	// and the path of the file. It is never called concurrently.
+	Progress func(phase string, done, total int, current string)
+	// Skipped is called for each move that Execute leaves undone
+	// without failing, such as a duplicate or a file open elsewhere,
+	// with the reason it was skipped.
+	Skipped func(m Move, reason string)
+	// Moved is called for each move that Execute makes,
+	// with the destination it was moved to.
+	Moved func(m Move)
+	// Now returns the current time for OlderThan and NewerThan.
+	// It defaults to time.Now.
+	Now func() time.Time
+	// Source, Metadata, and Mover are how the files are found, dated,
+	// and moved. They default to the real file system.
+	Source   FileSource
+	Metadata MetadataProvider
+	Mover    Mover
+	// Logger receives messages about files that are skipped or moved.
+	// It defaults to discarding them.
+	Logger *slog.Logger
+}
+
+// runner is Options with the defaults filled in.
+type runner struct {
+	Options
+	dir      string
+	template *template.Template
+	rename   *template.Template
+	// needsSource is set if the templates use .Source
+	needsSource bool
+	// needsApp is set if the templates use .App
+	needsApp bool
+	// sizeRoutes are SizeRoutes and the size routes in Kinds, most specific first
+	sizeRoutes []SizeRoute
+	ignore     ignorer
+	// run is the ID of the run executing moves
+	run string
+	// datesAdded holds dates looked up ahead of time by path
+	datesAdded map[string]time.Time
+	// syncClients holds the sync client of each folder looked up, if any
+	syncClients map[string]string
+}
+
+func (o Options) runner(dir string) (*runner, error) {
+	r := &runner{Options: o, dir: dir}
+	if r.Now == nil {
+		r.Now = time.Now
+	}
+	if r.Source == nil {
+		r.Source = osFiles{}
+	}
+	if r.Metadata == nil {
+		r.Metadata = osMetadata{}
+	}
+	if r.Mover == nil {
+		r.Mover = osMover{}
+	}
+	if r.Dest == "" {
+		r.Dest = dir
+	}
+	if r.DateLayout == "" {
+		r.DateLayout = "month"
+	}
+	if r.Template == "" {
+		var ok bool
+		if r.Template, ok = dateLayouts[r.DateLayout]; !ok {
+			return nil, fmt.Errorf("unknown date layout %q", r.DateLayout)
+		}
+	}
+	if r.AirDrop {
+		r.ByApp = true
+		r.OlderThan, r.NewerThan = 0, 0
+	}
+	if r.ByApp {
+		r.Template = "{{.App}}/" + r.Template
+	}
+	t, err := parseTemplate(r.Template)
+	if err != nil {
+		return nil, err
+	}
+	r.template = t
+	r.needsSource = strings.Contains(r.Template+r.RenameTemplate, ".Source")
+	r.needsApp = strings.Contains(r.Template+r.RenameTemplate, ".App")
+	if r.RenameTemplate != "" {
+		if r.rename, err = parseTemplate(r.RenameTemplate); err != nil {
+			return nil, err
+		}
+	}
+	if r.KeepInPlace == nil {
+		r.KeepInPlace = defaultExcludes
+	}
+	patterns := slices.Clone(r.KeepInPlace)
+	if dir != "" {
+		lines, err := readIgnoreFile(r.Source, dir)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, lines...)
+	}
+	patterns = append(patterns, r.Exclude...)
+	if r.ignore, err = parseIgnore(patterns); err != nil {
+		return nil, err
+	}
+	if r.Kinds == nil {
+		r.Kinds = NewKinds()
+	}
+	r.sizeRoutes = slices.Clone(r.SizeRoutes)
+	sortSizeRoutes(r.sizeRoutes)
+	r.sizeRoutes = append(r.sizeRoutes, r.Kinds.sizeRoutes...)
+	if r.ProjectMarkers == nil {
+		r.ProjectMarkers = defaultProjectMarkers
+	}
+	if r.DateSources == nil {
+		r.DateSources = defaultDateSources
+	}
+	for _, source := range r.DateSources {
+		if _, ok := dateSources[source]; !ok {
+			return nil, fmt.Errorf("unknown date source %q", source)
+		}
+	}
+	if r.TagColor == "" {
+		r.TagColor = "none"
+	}
+	if _, ok := tagColors[r.TagColor]; !ok {
+		return nil, fmt.Errorf("unknown tag color %q", r.TagColor)
+	}
+	if r.OnDateError == "" {
+		r.OnDateError = DateErrorMtime
+	}
+	if !slices.Contains(dateErrorModes, r.OnDateError) {
+		return nil, fmt.Errorf("unknown date error mode %q", r.OnDateError)
+	}
+	if r.Checksum == "" {
+		r.Checksum = "none"
+	}
+	if !slices.Contains(checksumAlgorithms, r.Checksum) {
+		return nil, fmt.Errorf("unknown checksum %q", r.Checksum)
+	}
+	if r.ICloud == "" {
+		r.ICloud = ICloudSkip
+	}
+	if !slices.Contains(icloudModes, r.ICloud) {
+		return nil, fmt.Errorf("unknown iCloud mode %q", r.ICloud)
+	}
+	if r.Classify == "" {
+		r.Classify = ClassifyExt
+	}
+	if !slices.Contains(classifiers, r.Classify) {
+		return nil, fmt.Errorf("unknown classifier %q", r.Classify)
+	}
+	if r.Order == "" {
+		r.Order = OrderDestination
+	}
+	if !slices.Contains(orders, r.Order) {
+		return nil, fmt.Errorf("unknown order %q", r.Order)
+	}
+	if r.Limit < 0 {
+		return nil, fmt.Errorf("bad limit %d", r.Limit)
+	}
+	if r.LeaveSymlink > 0 && runtime.GOOS == "windows" {
+		// Without extended attributes, the links can't be told apart
+		// from other links, so they would be moved on the next run
+		return nil, errors.New("leaving links is not supported on Windows")
+	}
+	if r.OnConflict == "" {
+		r.OnConflict = ConflictRename
+	}
+	if !slices.Contains(conflictStrategies, r.OnConflict) {
+		return nil, fmt.Errorf("unknown conflict strategy %q", r.OnConflict)
+	}
+	if err := checkSanitize(r.Sanitize); err != nil {
+		return nil, err
+	}
+	if r.DirMode == 0 {
+		r.DirMode = defaultDirMode
+	}
+	if r.DirMode&^fs.ModePerm != 0 {
+		return nil, fmt.Errorf("bad folder mode %v", r.DirMode)
+	}
+	if r.Jobs < 1 {
+		r.Jobs = runtime.NumCPU()
+	}
+	if r.Progress == nil {
+		r.Progress = func(string, int, int, string) {}
+	}
+	if r.Skipped == nil {
+		r.Skipped = func(Move, string) {}
+	}
+	if r.Moved == nil {
+		r.Moved = func(Move) {}
+	}
+	if r.Logger == nil {
+		r.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	return r, nil
+}
+
+// Move is a planned change of a file's location.
+type Move struct {
+	Old  string    `json:"source"`
+	New  string    `json:"destination"`
+	Kind string    `json:"kind"` // empty for directories
+	Date time.Time `json:"date"`
+	Size int64     `json:"size"`
+	// Duplicate means the file at New has the same contents,
+	// so the file is left in place or trashed instead of moved.
+	Duplicate bool `json:"duplicate,omitempty"`
+	// Checksum is the hex encoded SHA-256 of the file's contents,
+	// if Options.Checksum is set.
+	Checksum string `json:"checksum,omitempty"`
+	// Sanitized means characters in New that other file systems
+	// don't allow were replaced following Options.Sanitize.
+	Sanitized bool `json:"sanitized,omitempty"`
+	// renamed means New was renamed to avoid a conflict while planning.
+	renamed bool
+}
+
+// Plan returns the moves that organize the contents of dir,
+// sorted by Options.Order.
+func Plan(dir string, opts Options) ([]Move, error) {
+	r, err := opts.runner(dir)
+	if err != nil {
+		return nil, err
+	}
+	return r.plan()
+}
+
+func (r *runner) plan() ([]Move, error) {
+	paths, dirpaths, err := r.scan()
+	if err != nil {
+		return nil, err
+	}
+	paths = r.handlePlaceholders(paths)
+	// Only the real file system has dates added to look up ahead of time
+	if _, ok := r.Metadata.(osMetadata); ok && slices.Contains(r.DateSources, "added") {
+		r.prefetchDatesAdded(slices.Concat(paths, dirpaths))
+	}
+	built, err := r.buildMoves(paths, dirpaths)
+	if err != nil {
+		return nil, err
+	}
+	r.groupCompanions(built)
+	var moves []Move
+	for _, m := range built {
+		if reason := r.filter(m); reason != "" {
+			r.Logger.Debug("skipping", "old", m.Old, "reason", reason)
+			continue
+		}
+		moves = append(moves, m)
+	}
+
+	moves, err = r.resolveConflicts(moves)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.arrange(moves), nil
+}
+
+// planDirs combines the moves returned by plan for each of dirs,
+// resolving conflicts between their destinations.
+func planDirs(dirs []string, opts Options, plan func(dir string, opts Options) ([]Move, error)) ([]Move, error) {
+	if len(dirs) == 1 {
+		return plan(dirs[0], opts)
+	}
+	var moves []Move
+	for _, dir := range dirs {
+		planned, err := plan(dir, opts)
+		if err != nil {
+			return nil, fmt.Errorf("planning %q: %w", dir, err)
+		}
+		moves = append(moves, planned...)
+	}
+	r, err := opts.runner(dirs[0])
+	if err != nil {
+		return nil, err
+	}
+	if moves, err = r.resolveConflicts(moves); err != nil {
+		return nil, err
+	}
+	return r.arrange(moves), nil
+}
+
+// resolveConflicts applies r.OnConflict to moves with destinations
+// that already exist or that are the same as an earlier move's.
+// Destinations that differ only by case or Unicode normalization are
+// the same where the file system treats them so.
+func (r *runner) resolveConflicts(moves []Move) ([]Move, error) {
+	resolved := moves[:0]
+	claimed := make(pathSet, len(moves))
+	claimedBy := make(map[string]string, len(moves)) // by pathKey
+	for _, m := range moves {
+		if isPhotosImport(m.New) {
+			resolved = append(resolved, m)
+			continue
+		}
+		if r.Dedupe {
+			dup, err := r.isDuplicate(m, claimedBy)
+			if err != nil {
+				return nil, err
+			}
+			if dup {
+				r.Logger.Debug("found duplicate", "old", m.Old, "new", m.New)
+				m.Duplicate = true
+				resolved = append(resolved, m)
+				continue
+			}
+		}
+		strategy := r.OnConflict
+		if claimed.has(m.New) {
+			r.Logger.Info("same destination as another file", "old", m.Old, "new", m.New)
+			// Never overwrite a file that is being organized
+			if strategy == ConflictOverwrite || strategy == ConflictTrash {
+				strategy = ConflictRename
+			}
+		}
+		newpath, err := resolveConflict(r.Source, strategy, m.New, claimed)
+		if err != nil {
+			return nil, err
+		}
+		if newpath == "" {
+			r.Logger.Debug("skipping", "old", m.Old, "reason", "destination exists")
+			continue
+		}
+		m.renamed = newpath != m.New
+		m.New = newpath
+		claimed.add(newpath)
+		claimedBy[pathKey(newpath)] = m.Old
+		resolved = append(resolved, m)
+	}
+	return resolved, nil
+}
+
+func (r *runner) scan() (paths, dirpaths []string, err error) {
+	if r.MDQuery != "" {
+		dir, err := filepath.Abs(r.dir)
+		if err != nil {
+			return nil, nil, err
+		}
+		results, err := mdfind(dir, r.MDQuery)
+		if err != nil {
+			return nil, nil, err
+		}
+		return r.choose(results)
+	}
+	if r.Files != nil {
+		return r.choose(r.Files)
+	}
+	if r.Recursive {
+		paths, err = r.walk()
+		return paths, nil, err
+	}
+	entries, err := r.Source.ReadDir(r.dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if r.skipHidden(name) {
+			continue
+		}
+		if r.ignore.ignored(name, entry.IsDir()) {
+			r.Logger.Debug("skipping", "path", name, "reason", "excluded")
+			continue
+		}
+		path := filepath.Join(r.dir, name)
+		if entry.Type()&fs.ModeSymlink != 0 && isLeftLink(r.Source, path) {
+			continue
+		}
+		if !entry.IsDir() || isPackage(path) {
+			paths = append(paths, path)
+			continue
+		}
+		if r.ExcludeDirs || r.isArchiveDir(name) {
+			continue
+		}
+		if r.isProject(path) {
+			r.Logger.Debug("skipping", "path", name, "reason", "project")
+			continue
+		}
+		dirpaths = append(dirpaths, path)
+	}
+	return paths, dirpaths, nil
+}
+
+// choose returns the items among results, from r.MDQuery or r.Files,
+// that are in r.dir, or with r.Recursive, in it and its subdirectories,
+// skipping the same items as scan and walk.
+func (r *runner) choose(results []string) (paths, dirpaths []string, err error) {
+	dir, err := filepath.Abs(r.dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	dest, err := filepath.Abs(r.Dest)
+	if err != nil {
+		return nil, nil, err
+	}
+	abs := make([]string, 0, len(results))
+	for _, result := range results {
+		if result, err = filepath.Abs(result); err != nil {
+			return nil, nil, err
+		}
+		abs = append(abs, result)
+	}
+	slices.Sort(abs)
+	abs = slices.Compact(abs)
+	var skipped []string
+	for _, result := range abs {
+		rel, err := filepath.Rel(dir, result)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		name := filepath.ToSlash(rel)
+		segments := strings.Split(name, "/")
+		if len(segments) > 1 && !r.Recursive {
+			continue
+		}
+		if r.MaxDepth > 0 && len(segments) > r.MaxDepth {
+			continue
+		}
+		// Results are sorted, so folders come before their contents
+		if slices.ContainsFunc(skipped, func(s string) bool {
+			return strings.HasPrefix(name, s+"/")
+		}) {
+			continue
+		}
+		info, err := r.Source.Lstat(result)
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(r.dir, rel)
+		isDir := info.IsDir() && !isPackage(path)
+		if slices.ContainsFunc(segments, r.skipHidden) ||
+			r.isArchiveDir(segments[0]) ||
+			r.ignore.ignored(name, info.IsDir()) ||
+			isDir && r.isProject(path) ||
+			info.IsDir() && result == dest ||
+			info.Mode()&fs.ModeSymlink != 0 && isLeftLink(r.Source, path) {
+			if info.IsDir() {
+				skipped = append(skipped, name)
+			}
+			continue
+		}
+		switch {
+		case !isDir:
+			paths = append(paths, path)
+			if info.IsDir() {
+				// Packages are moved whole
+				skipped = append(skipped, name)
+			}
+		case !r.ExcludeDirs && !r.Recursive:
+			dirpaths = append(dirpaths, path)
+		}
+	}
+	return paths, dirpaths, nil
+}
+
+// walk returns the files in r.dir and its subdirectories,
+// skipping the year folders that Scooter has already organized.
+// Packages are returned as files, without their contents,
+// and projects are skipped.
+func (r *runner) walk() (paths []string, err error) {
+	fsys := subFS{r.Source, r.dir}
+	dest, err := filepath.Abs(r.Dest)
+	if err != nil {
+		return nil, err
+	}
+	err = fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name == "." {
+			return nil
+		}
+		if r.skipHidden(d.Name()) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if r.ignore.ignored(name, d.IsDir()) {
+			r.Logger.Debug("skipping", "path", name, "reason", "excluded")
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		path := filepath.Join(r.dir, filepath.FromSlash(name))
+		depth := strings.Count(name, "/") + 1
+		if d.IsDir() {
+			if abs, _ := filepath.Abs(path); abs == dest {
+				return fs.SkipDir
+			}
+			if depth == 1 && r.isArchiveDir(name) {
+				return fs.SkipDir
+			}
+			if isPackage(path) {
+				paths = append(paths, path)
+				return fs.SkipDir
+			}
+			if r.isProject(path) {
+				r.Logger.Debug("skipping", "path", name, "reason", "project")
+				return fs.SkipDir
+			}
+			if r.MaxDepth > 0 && depth >= r.MaxDepth {
+				r.Logger.Debug("skipping", "path", name, "reason", "deeper than -max-depth")
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.Type()&fs.ModeSymlink != 0 && isLeftLink(r.Source, path) {
+			return nil
+		}
+		paths = append(paths, path)
+		return nil
+	})
+	return paths, err
+}
+
+// buildMoves calls buildMove for paths and dirpaths using up to r.Jobs workers.
+// The moves are returned in the same order as the paths.
+func (r *runner) buildMoves(paths, dirpaths []string) ([]Move, error) {
+	n := len(paths) + len(dirpaths)
+	moves := make([]Move, n)
+	errs := make([]error, n)
+	sem := make(chan struct{}, r.Jobs)
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		done int
+	)
+	for i := range n {
+		path, isDir := "", false
+		if i < len(paths) {
+			path = paths[i]
+		} else {
+			path, isDir = dirpaths[i-len(paths)], true
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			moves[i], errs[i] = r.buildMove(path, isDir)
+			mu.Lock()
+			defer mu.Unlock()
+			done++
+			r.Progress("scanning", done, n, path)
+		}()
+	}
+	wg.Wait()
+	built := moves[:0]
+	for i, err := range errs {
+		var merr *MetadataError
+		if errors.As(err, &merr) && r.OnDateError == DateErrorSkip {
+			r.Logger.Warn("skipping", "path", merr.Path, "reason", "no date", "error", merr.Err)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		built = append(built, moves[i])
+	}
+	return built, nil
+}
+
+// buildMove returns the move for path with its destination from r.template.
+func (r *runner) buildMove(path string, isDir bool) (Move, error) {
+	kind := ""
+	if name, ok := icloudName(filepath.Base(path)); ok && !isDir {
+		kind = r.Kinds.Kind(name)
+	} else if !isDir {
+		kind = r.getKind(path)
+	}
+	date, err := r.getDate(path, kind)
+	if err != nil {
+		return Move{}, err
+	}
+	if isDir && r.DirDate != "" && r.DirDate != "added" {
+		if date, err = r.getDirDate(path, date); err != nil {
+			return Move{}, err
+		}
+	}
+	size, err := r.diskUsage(path)
+	if err != nil {
+		return Move{}, err
+	}
+	newpath, sanitized, err := r.destination(path, kind, date, size)
+	if err != nil {
+		return Move{}, err
+	}
+	sum := ""
+	if r.Checksum == ChecksumSHA256 && !isDir && !isPlaceholder(path) {
+		if sum, err = fileChecksum(r.Source, path); err != nil {
+			return Move{}, err
+		}
+	}
+	return Move{
+		Old:       path,
+		New:       newpath,
+		Kind:      kind,
+		Date:      date,
+		Size:      size,
+		Checksum:  sum,
+		Sanitized: sanitized,
+	}, nil
+}
+
+// destination returns where r.template puts the file at path
+// under the root for its kind and size, renamed by r.rename if it is set,
+// or the Photos library with r.ImportPhotos. It reports whether the name
+// was changed to remove characters that r.Sanitize replaces.
+func (r *runner) destination(path, kind string, date time.Time, size int64) (newpath string, sanitized bool, err error) {
+	name := filepath.Base(path)
+	if r.ImportPhotos && slices.Contains(photosKinds, kind) {
+		return PhotosPrefix + name, false, nil
+	}
+	// Placeholders are named for the file they stand in for
+	realName, isStub := icloudName(name)
+	if isStub {
+		name = realName
+	}
+	data := newTemplateData(name, kind, date)
+	if r.needsSource {
+		data.Source = getSource(r.Source, path)
+	}
+	if r.needsApp {
+		data.App = getApp(r.Source, path)
+	}
+	dir, err := execTemplate(r.template, data)
+	if err != nil {
+		return "", false, err
+	}
+	if r.rename != nil {
+		if name, err = execRenameTemplate(r.rename, data); err != nil {
+			return "", false, err
+		}
+	}
+	if r.Sanitize != "" {
+		var changed bool
+		dir, sanitized = sanitizePath(dir, r.Sanitize)
+		name, changed = sanitizePath(name, r.Sanitize)
+		sanitized = sanitized || changed
+	}
+	if isStub {
+		name = "." + name + ".icloud"
+	}
+	// Don't let odd names or templates escape the root with ..
+	rel := filepath.Join(filepath.FromSlash(dir), name)
+	if !filepath.IsLocal(rel) {
+		return "", false, fmt.Errorf("destination %q for %q is outside of its folder", rel, path)
+	}
+	return filepath.Join(r.root(kind, size), rel), sanitized, nil
+}
+
+// root returns the root of the destinations for files of kind and size.
+func (r *runner) root(kind string, size int64) string {
+	if kind == "" {
+		return r.Dest
+	}
+	root := r.Dest
+	if route, ok := r.Routes[kind]; ok {
+		root = expandHome(route)
+	} else if route, ok := r.Kinds.routes[kind]; ok {
+		root = expandHome(route)
+	}
+	if dir, ok := r.sizeRoute(kind, size); ok {
+		dir = expandHome(dir)
+		if filepath.IsAbs(dir) {
+			return dir
+		}
+		return filepath.Join(root, dir)
+	}
+	return root
+}
+
+// isDuplicate reports whether m.Old has the same contents as the file
+// at its destination, or the file in claimedBy planned to move there.
+func (r *runner) isDuplicate(m Move, claimedBy map[string]string) (bool, error) {
+	other := m.New
+	if src, ok := claimedBy[pathKey(m.New)]; ok {
+		other = src
+	}
+	return r.sameContents(m.Old, other)
+}
+
+// sameContents reports whether a and b are regular files with the same contents.
+// The checksums are only computed if the sizes match.
+func (r *runner) sameContents(a, b string) (bool, error) {
+	ainfo, err := r.Source.Lstat(a)
+	if err != nil {
+		return false, err
+	}
+	binfo, err := r.Source.Lstat(b)
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ENOTDIR) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !ainfo.Mode().IsRegular() || !binfo.Mode().IsRegular() ||
+		ainfo.Size() != binfo.Size() || os.SameFile(ainfo, binfo) {
+		return false, nil
+	}
+	asum, err := fileChecksum(r.Source, a)
+	if err != nil {
+		return false, err
+	}
+	bsum, err := fileChecksum(r.Source, b)
+	if err != nil {
+		return false, err
+	}
+	return asum == bsum, nil
+}
+
+// diskUsage returns the size of the file or directory tree at path.
+func (r *runner) diskUsage(path string) (size int64, err error) {
+	err = fs.WalkDir(subFS{r.Source, path}, ".", func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,307 @@
+package mvfiles
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"maps"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var testDate = time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)
+
+// newTestFS returns a MemFS holding files, by slash separated path,
+// with their contents, all modified at testDate.
+func newTestFS(t *testing.T, files map[string]string) *MemFS {
+	t.Helper()
+	m := &MemFS{Now: FixedClock(testDate)}
+	for name, data := range files {
+		if err := m.WriteFile(filepath.FromSlash(name), []byte(data), testDate); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return m
+}
+
+// memOptions returns Options that plan and move the files in m.
+func memOptions(m *MemFS) Options {
+	return Options{Source: m, Metadata: m, Mover: m, Now: FixedClock(testDate), Jobs: 1}
+}
+
+// fakeOptions returns Options that plan the files in m
+// dated by meta from when they were added, as of now.
+func fakeOptions(m *MemFS, meta *FakeMetadata, now time.Time) Options {
+	opts := memOptions(m)
+	opts.Metadata = meta
+	opts.DateSources = []string{"added"}
+	opts.Now = FixedClock(now)
+	return opts
+}
+
+// added returns FakeMetadata with the dates added of files,
+// by slash separated path.
+func added(dates map[string]time.Time) *FakeMetadata {
+	meta := &FakeMetadata{Dates: make(map[string]map[string]time.Time)}
+	for name, date := range dates {
+		meta.Dates[filepath.FromSlash(name)] = map[string]time.Time{"added": date}
+	}
+	return meta
+}
+
+// planned returns the destinations of moves by source,
+// as slash separated paths.
+func planned(moves []Move) map[string]string {
+	dests := make(map[string]string, len(moves))
+	for _, m := range moves {
+		dests[filepath.ToSlash(m.Old)] = filepath.ToSlash(m.New)
+	}
+	return dests
+}
+
+func checkPlan(t *testing.T, moves []Move, want map[string]string) {
+	t.Helper()
+	if got := planned(moves); !maps.Equal(got, want) {
+		t.Errorf("planned %v\nwant %v", got, want)
+	}
+}
+
+func TestPlanScan(t *testing.T) {
+	m := newTestFS(t, map[string]string{
+		"/in/a.pdf":               "a",
+		"/in/b.jpg":               "b",
+		"/in/.hidden":             "",
+		"/in/notes/x.txt":         "x",
+		"/in/project/go.mod":      "module x",
+		"/in/2023/03/doc/old.pdf": "old",
+		"/in/keep.log":            "",
+		"/in/" + IgnoreFile:       "*.log\n",
+		"/in/Photo.app/Contents":  "",
+	})
+	moves, err := Plan(filepath.FromSlash("/in"), memOptions(m))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkPlan(t, moves, map[string]string{
+		"/in/a.pdf":     "/in/2024/03/doc/a.pdf",
+		"/in/b.jpg":     "/in/2024/03/image/b.jpg",
+		"/in/notes":     "/in/2024/03/notes",
+		"/in/Photo.app": "/in/2024/03/app/Photo.app",
+	})
+}
+
+func TestPlanRecursive(t *testing.T) {
+	m := newTestFS(t, map[string]string{
+		"/in/a.pdf":             "a",
+		"/in/notes/x.txt":       "x",
+		"/in/notes/deep/y.jpg":  "y",
+		"/in/notes/.git/config": "",
+	})
+	opts := memOptions(m)
+	opts.Recursive = true
+	opts.ProjectMarkers = []string{}
+	moves, err := Plan(filepath.FromSlash("/in"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkPlan(t, moves, map[string]string{
+		"/in/a.pdf":            "/in/2024/03/doc/a.pdf",
+		"/in/notes/x.txt":      "/in/2024/03/doc/x.txt",
+		"/in/notes/deep/y.jpg": "/in/2024/03/image/y.jpg",
+	})
+}
+
+// quarantined is a quarantine set by Safari on a download
+// that hasn't been opened, opened is the same after it was,
+// and airDropped is an unopened file received over AirDrop.
+const (
+	quarantined = "0081;6650a1b2;Safari;"
+	opened      = "00c1;6650a1b2;Safari;"
+	airDropped  = "0081;6650a1b2;sharingd;"
+)
+
+func TestPlanFilters(t *testing.T) {
+	m := newTestFS(t, map[string]string{
+		"/in/a.pdf":  "a",
+		"/in/b.jpg":  "b",
+		"/in/c.zip":  "c",
+		"/in/d.zip":  "d",
+		"/in/e.heic": "e",
+		"/in/f.mp4":  "f",
+	})
+	for name, q := range map[string]string{"c.zip": quarantined, "d.zip": opened, "e.heic": airDropped} {
+		if err := m.SetXattr(filepath.FromSlash("/in/"+name), quarantineXattr, []byte(q)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, tc := range []struct {
+		name string
+		set  func(*Options)
+		want []string
+	}{
+		{"all", func(*Options) {}, []string{"a.pdf", "b.jpg", "c.zip", "d.zip", "e.heic", "f.mp4"}},
+		{"only kinds", func(o *Options) { o.OnlyKinds = []string{"image", "video"} }, []string{"b.jpg", "e.heic", "f.mp4"}},
+		{"skip kinds", func(o *Options) { o.SkipKinds = []string{"archive"} }, []string{"a.pdf", "b.jpg", "e.heic", "f.mp4"}},
+		{"skip quarantined", func(o *Options) { o.SkipQuarantined = true }, []string{"a.pdf", "b.jpg", "d.zip", "f.mp4"}},
+		{"airdrop", func(o *Options) { o.AirDrop = true }, []string{"e.heic"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := memOptions(m)
+			tc.set(&opts)
+			moves, err := Plan(filepath.FromSlash("/in"), opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[string]bool)
+			for _, mv := range moves {
+				got[filepath.Base(mv.Old)] = true
+			}
+			want := make(map[string]bool)
+			for _, name := range tc.want {
+				want[name] = true
+			}
+			if !maps.Equal(got, want) {
+				t.Errorf("planned %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestPlanConflicts(t *testing.T) {
+	m := newTestFS(t, map[string]string{
+		"/in/a.pdf":                "new",
+		"/in/sub/a.pdf":            "other",
+		"/in/2024/03/doc/a.pdf":    "existing",
+		"/in/same.pdf":             "same",
+		"/in/2024/03/doc/same.pdf": "same",
+	})
+	opts := memOptions(m)
+	opts.Recursive = true
+	moves, err := Plan(filepath.FromSlash("/in"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkPlan(t, moves, map[string]string{
+		"/in/a.pdf":     "/in/2024/03/doc/a (1).pdf",
+		"/in/sub/a.pdf": "/in/2024/03/doc/a (2).pdf",
+		"/in/same.pdf":  "/in/2024/03/doc/same (1).pdf",
+	})
+
+	opts.Dedupe = true
+	moves, err = Plan(filepath.FromSlash("/in"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mv := range moves {
+		if dup := filepath.Base(mv.Old) == "same.pdf"; mv.Duplicate != dup {
+			t.Errorf("%s: duplicate = %v, want %v", mv.Old, mv.Duplicate, dup)
+		}
+	}
+}
+
+func TestPlanChecksum(t *testing.T) {
+	m := newTestFS(t, map[string]string{"/in/a.txt": "hello\n"})
+	opts := memOptions(m)
+	opts.Checksum = ChecksumSHA256
+	moves, err := Plan(filepath.FromSlash("/in"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"
+	if len(moves) != 1 || moves[0].Checksum != want {
+		t.Errorf("planned %+v, want checksum %s", moves, want)
+	}
+}
+
+func TestExecute(t *testing.T) {
+	m := newTestFS(t, map[string]string{
+		"/in/a.pdf":       "a",
+		"/in/b.jpg":       "b",
+		"/in/notes/x.txt": "x",
+	})
+	opts := memOptions(m)
+	moves, err := Plan(filepath.FromSlash("/in"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var moved []string
+	opts.Moved = func(mv Move) { moved = append(moved, filepath.ToSlash(mv.Old)) }
+	if err = Execute(context.Background(), moves, opts); err != nil {
+		t.Fatal(err)
+	}
+	if len(moved) != len(moves) {
+		t.Errorf("moved %v, want all %d moves", moved, len(moves))
+	}
+	for old, dest := range planned(moves) {
+		if _, err := m.Lstat(filepath.FromSlash(old)); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("%s still exists: %v", old, err)
+		}
+		if _, err := m.Lstat(filepath.FromSlash(dest)); err != nil {
+			t.Errorf("%s not moved to %s: %v", old, dest, err)
+		}
+	}
+	if _, err := m.Lstat(filepath.FromSlash("/in/2024/03/notes/x.txt")); err != nil {
+		t.Errorf("contents of folder not moved: %v", err)
+	}
+}
+
+func TestExecuteCopy(t *testing.T) {
+	m := newTestFS(t, map[string]string{"/in/a.pdf": "a"})
+	opts := memOptions(m)
+	opts.Copy = true
+	moves, err := Plan(filepath.FromSlash("/in"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = Execute(context.Background(), moves, opts); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"/in/a.pdf", "/in/2024/03/doc/a.pdf"} {
+		if _, err := m.Lstat(filepath.FromSlash(name)); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestExecuteFailures(t *testing.T) {
+	newPlan := func(t *testing.T) (*MemFS, []Move) {
+		m := newTestFS(t, map[string]string{"/in/a.pdf": "a", "/in/b.pdf": "b", "/in/c.pdf": "c"})
+		moves := []Move{
+			{Old: filepath.FromSlash("/in/a.pdf"), New: filepath.FromSlash("/out/a.pdf")},
+			// A file is in the way of the folder for b
+			{Old: filepath.FromSlash("/in/b.pdf"), New: filepath.FromSlash("/in/c.pdf/b.pdf")},
+			{Old: filepath.FromSlash("/in/c.pdf"), New: filepath.FromSlash("/out/c.pdf")},
+		}
+		return m, moves
+	}
+	t.Run("stop", func(t *testing.T) {
+		m, moves := newPlan(t)
+		var moved int
+		opts := memOptions(m)
+		opts.Moved = func(Move) { moved++ }
+		err := Execute(context.Background(), moves, opts)
+		var fm *FailedMove
+		if !errors.As(err, &fm) || filepath.Base(fm.Old) != "b.pdf" {
+			t.Fatalf("got %v, want b.pdf to fail", err)
+		}
+		if moved != 1 {
+			t.Errorf("moved %d, want 1", moved)
+		}
+	})
+	t.Run("keep going", func(t *testing.T) {
+		m, moves := newPlan(t)
+		var moved int
+		opts := memOptions(m)
+		opts.KeepGoing = true
+		opts.Moved = func(Move) { moved++ }
+		err := Execute(context.Background(), moves, opts)
+		var fme *FailedMovesError
+		if !errors.As(err, &fme) || len(fme.Failures) != 1 || fme.Total != 3 {
+			t.Fatalf("got %v, want 1 of 3 moves to fail", err)
+		}
+		if moved != 2 {
+			t.Errorf("moved %d, want 2", moved)
+		}
+	})
+}
@@ -0,0 +1,142 @@
+package mvfiles
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// errPreflight is returned by Execute when a preflight check fails.
+var errPreflight = errors.New("preflight check failed, nothing was moved")
+
+// preflight checks that the moves can be made before any of them are.
+// The folders the moves go into, or their nearest existing parents,
+// must be writable, and each volume must have room for the files
+// coming to it from another volume and, with r.Copy, for the copies
+// made on the same volume when it can't clone them.
+// It reports every problem it finds.
+// Only moves on the real file system are checked.
+func (r *runner) preflight(moves []Move) error {
+	if _, ok := r.Mover.(osMover); !ok {
+		return nil
+	}
+	needs := make(map[string]int64)  // bytes copied into each folder
+	copies := make(map[string]int64) // bytes copied within the folder's volume
+	var errs []error
+	for _, m := range moves {
+		if m.Duplicate || isPhotosImport(m.New) {
+			continue
+		}
+		dir := existingParent(filepath.Dir(m.New))
+		if _, ok := needs[dir]; !ok {
+			needs[dir] = 0
+		}
+		from, err := volumeID(m.Old)
+		if errors.Is(err, fs.ErrNotExist) {
+			// Reported when the move is made
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		to, err := volumeID(dir)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		switch {
+		case from != to:
+			needs[dir] += m.Size
+		case r.Copy:
+			copies[dir] += m.Size
+		}
+	}
+	type volume struct {
+		dir          string
+		need, copies int64
+	}
+	volumes := make(map[string]*volume)
+	for dir, need := range needs {
+		if err := checkWritable(dir); err != nil {
+			errs = append(errs, fmt.Errorf("destination %q is not writable: %w", dir, err))
+			continue
+		}
+		id, err := volumeID(dir)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if volumes[id] == nil {
+			volumes[id] = &volume{dir: dir}
+		}
+		volumes[id].need += need
+		volumes[id].copies += copies[dir]
+	}
+	for _, v := range volumes {
+		if v.copies > 0 && !canClone(v.dir) {
+			v.need += v.copies
+		}
+		if v.need == 0 {
+			continue
+		}
+		free, err := diskFree(v.dir)
+		if errors.Is(err, errors.ErrUnsupported) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if v.need > free {
+			errs = append(errs, fmt.Errorf("not enough space for %q: %s needed but %s free",
+				v.dir, formatSize(v.need), formatSize(free)))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%w:\n%w", errPreflight, errors.Join(errs...))
+	}
+	return nil
+}
+
+// existingParent returns dir or its nearest parent that exists.
+func existingParent(dir string) string {
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		dir = parent
+	}
+}
+
+// canClone reports whether copies in dir can be clones,
+// which share their blocks with the original and take no space.
+func canClone(dir string) bool {
+	f, err := os.CreateTemp(dir, tempName(dir, "preflight-*"))
+	if err != nil {
+		return false
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+	clone := filepath.Join(dir, tempName(dir, "preflight-clone"))
+	_ = os.Remove(clone)
+	err = cloneFile(f.Name(), clone)
+	os.Remove(clone)
+	return err == nil
+}
+
+// checkWritable creates and removes a file in dir,
+// which fails if dir is read-only or its volume is full.
+func checkWritable(dir string) error {
+	f, err := os.CreateTemp(dir, tempName(dir, "preflight-*"))
+	if err != nil {
+		return err
+	}
+	return errors.Join(f.Close(), os.Remove(f.Name()))
+}
@@ -0,0 +1,34 @@
+package mvfiles
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPreflightCountsCopies(t *testing.T) {
+	dir := t.TempDir()
+	if canClone(dir) {
+		t.Skip("copies on this volume are clones that take no space")
+	}
+	if _, err := diskFree(dir); err != nil {
+		t.Skipf("free space unknown: %v", err)
+	}
+	old := filepath.Join(dir, "big.iso")
+	if err := os.WriteFile(old, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// Far more than any disk has free
+	moves := []Move{{Old: old, New: filepath.Join(dir, "2024", "03", "big.iso"), Size: 1 << 60}}
+	for _, copy := range []bool{false, true} {
+		r, err := Options{Copy: copy}.runner(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = r.preflight(moves)
+		if got := errors.Is(err, errPreflight); got != copy {
+			t.Errorf("copy %v: preflight returned %v", copy, err)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package mvfiles
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// progressBar prints a status line for a long running phase,
+// at most ten times a second.
+type progressBar struct {
+	w     io.Writer
+	phase string
+	start time.Time
+	last  time.Time
+}
+
+// isTerminal reports whether f is attached to a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// report is an Options.Progress function.
+func (p *progressBar) report(phase string, done, total int, current string) {
+	now := time.Now()
+	if phase != p.phase {
+		p.phase, p.start, p.last = phase, now, time.Time{}
+	}
+	finished := done == total
+	if !finished && now.Sub(p.last) < 100*time.Millisecond {
+		return
+	}
+	p.last = now
+	eta := "?"
+	if done > 0 {
+		elapsed := now.Sub(p.start)
+		remaining := elapsed * time.Duration(total-done) / time.Duration(done)
+		eta = remaining.Round(time.Second).String()
+	}
+	pct := 100
+	if total > 0 {
+		pct = done * 100 / total
+	}
+	// Carriage return and erase line
+	fmt.Fprintf(p.w, "\r\x1b[K%s %d/%d (%d%%) ETA %s %s", phase, done, total, pct, eta, filepath.Base(current))
+	if finished {
+		fmt.Fprintln(p.w)
+	}
+}
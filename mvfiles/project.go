@@ -0,0 +1,20 @@
+package mvfiles
+
+import "path/filepath"
+
+// defaultProjectMarkers are files that mark a directory as a project,
+// like a code checkout, that should stay where it is.
+var defaultProjectMarkers = []string{
+	".git", ".hg", ".svn", "go.mod", "package.json", "Cargo.toml",
+	"pyproject.toml", "Gemfile", "Makefile",
+}
+
+// isProject reports whether the directory at path holds any of r.ProjectMarkers.
+func (r *runner) isProject(path string) bool {
+	for _, marker := range r.ProjectMarkers {
+		if _, err := r.Source.Lstat(filepath.Join(path, marker)); err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package mvfiles
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// junkFiles are made by the system, or by -localize, and don't keep
+// a folder from being empty.
+var junkFiles = []string{".DS_Store", "Thumbs.db", "desktop.ini", LocalizedDir}
+
+// Prune removes the empty folders inside of dir, deepest first,
+// and returns their paths. Folders holding only junk files like .DS_Store
+// count as empty, but other hidden files are kept along with their folders.
+// If dryRun is set, Prune only returns the folders it would remove.
+func Prune(dir string, dryRun bool) ([]string, error) {
+	var dirs []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() || path == dir {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			return fs.SkipDir
+		}
+		dirs = append(dirs, path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	removed := make(map[string]bool)
+	var pruned []string
+	for _, d := range slices.Backward(dirs) {
+		entries, err := os.ReadDir(d)
+		if err != nil {
+			return pruned, err
+		}
+		empty := true
+		for _, e := range entries {
+			if !slices.Contains(junkFiles, e.Name()) && !removed[filepath.Join(d, e.Name())] {
+				empty = false
+				break
+			}
+		}
+		if !empty {
+			continue
+		}
+		if !dryRun {
+			// RemoveAll is safe because the folder only holds junk
+			if err = os.RemoveAll(d); err != nil {
+				return pruned, err
+			}
+		}
+		removed[d] = true
+		pruned = append(pruned, d)
+	}
+	return pruned, nil
+}
+
+// Prune removes the empty folders in app.dirs.
+func (app *appEnv) Prune(ctx context.Context) error {
+	for _, dir := range app.dirs {
+		pruned, err := Prune(dir, app.dryRun)
+		for _, d := range pruned {
+			if app.dryRun {
+				fmt.Println(d)
+			} else {
+				app.Info("removed empty folder", "path", d)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
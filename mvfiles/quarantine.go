@@ -0,0 +1,39 @@
+package mvfiles
+
+import (
+	"strconv"
+	"strings"
+)
+
+// quarantineXattr is set by macOS on downloaded files, with a value like
+// "0081;6650a1b2;Safari;" whose first field holds flags in hex.
+const quarantineXattr = "com.apple.quarantine"
+
+// quarantineUserApproved is the flag set once a quarantined file
+// has been opened and approved by the user.
+const quarantineUserApproved = 0x0040
+
+// quarantineKinds are the kinds Options.ClearQuarantine applies to.
+var quarantineKinds = []string{"archive", "installer"}
+
+// quarantine returns the fields of the quarantine on the file at path in src:
+// flags, time, the app that downloaded it, and an event ID.
+// It returns nil if the file isn't quarantined.
+func quarantine(src FileSource, path string) []string {
+	value, err := src.Xattr(path, quarantineXattr)
+	if err != nil {
+		return nil
+	}
+	return strings.Split(string(value), ";")
+}
+
+// isUnopenedQuarantine reports whether the file at path in src was downloaded
+// and is still quarantined because it hasn't been opened yet.
+func isUnopenedQuarantine(src FileSource, path string) bool {
+	fields := quarantine(src, path)
+	if fields == nil {
+		return false
+	}
+	flags, err := strconv.ParseUint(fields[0], 16, 32)
+	return err == nil && flags&quarantineUserApproved == 0
+}
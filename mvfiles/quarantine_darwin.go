@@ -0,0 +1,26 @@
+package mvfiles
+
+import (
+	"errors"
+	"io/fs"
+	"syscall"
+	"unsafe"
+)
+
+// clearQuarantine removes the quarantine from the file at path, if any.
+func clearQuarantine(path string) error {
+	p, err := syscall.BytePtrFromString(path)
+	if err != nil {
+		return err
+	}
+	n, err := syscall.BytePtrFromString(quarantineXattr)
+	if err != nil {
+		return err
+	}
+	_, _, errno := syscall.Syscall(syscall.SYS_REMOVEXATTR,
+		uintptr(unsafe.Pointer(p)), uintptr(unsafe.Pointer(n)), xattrNoFollow)
+	if errno != 0 && !errors.Is(errno, syscall.ENOATTR) {
+		return &fs.PathError{Op: "removexattr", Path: path, Err: errno}
+	}
+	return nil
+}
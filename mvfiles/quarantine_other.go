@@ -0,0 +1,8 @@
+//go:build !darwin
+
+package mvfiles
+
+// Only macOS quarantines downloaded files.
+func clearQuarantine(path string) error {
+	return nil
+}
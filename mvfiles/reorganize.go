@@ -0,0 +1,54 @@
+package mvfiles
+
+// PlanReorganize returns the moves that bring the year folders in dir
+// up to date with the current options, such as a new template or kinds,
+// sorted by Options.Order. Files already in the right place are left out.
+//
+// Files are dated as usual unless their date is outside of the year and month
+// of the folders they are in, as when the date they were added changed
+// because they were moved, in which case the folders' date is used.
+func PlanReorganize(dir string, opts Options) ([]Move, error) {
+	r, err := opts.runner(dir)
+	if err != nil {
+		return nil, err
+	}
+	return r.reorganize(true)
+}
+
+// reorganize returns the moves for PlanReorganize. Unless useLayoutDates is set,
+// files are dated only by their metadata.
+func (r *runner) reorganize(useLayoutDates bool) ([]Move, error) {
+	paths, dirpaths, err := r.scanLayout()
+	if err != nil {
+		return nil, err
+	}
+	built, err := r.buildMoves(paths, dirpaths)
+	if err != nil {
+		return nil, err
+	}
+	for i, m := range built {
+		start, end := layoutDates(r.dir, m.Old)
+		if useLayoutDates && !start.IsZero() && (m.Date.Before(start) || !m.Date.Before(end)) {
+			built[i].Date = start
+			if built[i].New, built[i].Sanitized, err = r.destination(m.Old, m.Kind, start, m.Size); err != nil {
+				return nil, err
+			}
+		}
+	}
+	r.groupCompanions(built)
+	var moves []Move
+	for _, m := range built {
+		if m.New == m.Old {
+			continue
+		}
+		if reason := r.filter(m); reason != "" {
+			r.Logger.Debug("skipping", "old", m.Old, "reason", reason)
+			continue
+		}
+		moves = append(moves, m)
+	}
+	if moves, err = r.resolveConflicts(moves); err != nil {
+		return nil, err
+	}
+	return r.arrange(moves), nil
+}
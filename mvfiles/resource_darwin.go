@@ -0,0 +1,58 @@
+//go:build cgo
+
+package mvfiles
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"unsafe"
+
+	"github.com/progrium/darwinkit/macos/foundation"
+	"github.com/progrium/darwinkit/objc"
+)
+
+// cocoaErrors are the NSCocoaErrorDomain codes that mean common errors.
+var cocoaErrors = map[int]error{
+	4:   fs.ErrNotExist,   // NSFileNoSuchFileError
+	257: fs.ErrPermission, // NSFileReadNoPermissionError
+	260: fs.ErrNotExist,   // NSFileReadNoSuchFileError
+}
+
+// resourceValue returns the value of the resource key of the file at path
+// as converted by convert. It uses resourceValuesForKeys:error:,
+// which returns the value in a dictionary instead of writing it through
+// an out parameter, as getResourceValue:forKey:error: does.
+// convert is called inside an autorelease pool, so the object it is passed
+// must not be kept after it returns.
+func resourceValue[T any](path string, key foundation.URLResourceKey, convert func(objc.Object) (T, error)) (v T, err error) {
+	s := strings.Clone(path)
+	objc.WithAutoreleasePool(func() {
+		var values map[foundation.URLResourceKey]objc.Object
+		url := foundation.NewURLFileURLWithPath(s)
+		nserr := withOutParams(1, func(out []unsafe.Pointer) {
+			values = url.ResourceValuesForKeysError([]foundation.URLResourceKey{key}, out[0])
+		})[0]
+		obj, ok := values[key]
+		switch {
+		case values == nil && nserr != nil:
+			err = &fs.PathError{Op: string(key), Path: path, Err: cocoaError(foundation.ErrorFrom(nserr))}
+		case !ok || obj.IsNil():
+			err = &fs.PathError{Op: string(key), Path: path, Err: errors.New("no value")}
+		default:
+			v, err = convert(obj)
+		}
+	})
+	return v, err
+}
+
+// cocoaError returns the Go error for e.
+func cocoaError(e foundation.Error) error {
+	if e.Domain() == "NSCocoaErrorDomain" {
+		if err, ok := cocoaErrors[e.Code()]; ok {
+			return err
+		}
+	}
+	return fmt.Errorf("%s (%s %d)", e.LocalizedDescription(), e.Domain(), e.Code())
+}
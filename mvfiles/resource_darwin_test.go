@@ -0,0 +1,29 @@
+//go:build cgo
+
+package mvfiles
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResourceValue(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "report.pdf")
+	if err := os.WriteFile(name, []byte("%PDF-1.4\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	kind, err := getUTIKind(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kind != "doc" {
+		t.Errorf("getUTIKind(%q) = %q, want doc", name, kind)
+	}
+	_, err = getUTIKind(filepath.Join(filepath.Dir(name), "missing.pdf"))
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing file: got %v, want fs.ErrNotExist", err)
+	}
+}
@@ -0,0 +1,138 @@
+package mvfiles
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// interruptedRun returns the most recent run in entries if it was interrupted
+// before it ended, along with its entries, the moves it intended to make
+// that it did not record making, and the settings it started with,
+// which are nil for runs journaled before settings were recorded.
+func interruptedRun(entries []journalEntry) (run []journalEntry, remaining []Move, settings url.Values) {
+	id := ""
+	for _, e := range slices.Backward(entries) {
+		if e.Action == actionIntent || e.Action == actionEnd {
+			if e.Action == actionIntent {
+				id = e.Run
+			}
+			break
+		}
+	}
+	if id == "" {
+		return nil, nil, nil
+	}
+	done := make(map[string]bool)
+	for _, e := range entries {
+		if e.Run != id {
+			continue
+		}
+		run = append(run, e)
+		if slices.Contains(undoableActions, e.Action) {
+			done[e.Old] = true
+		}
+		if e.Action == actionStart {
+			settings, _ = url.ParseQuery(e.New)
+		}
+	}
+	for _, e := range run {
+		// Resuming records the intents again
+		if e.Action == actionIntent && !done[e.Old] {
+			done[e.Old] = true
+			remaining = append(remaining, Move{Old: e.Old, New: e.New})
+		}
+	}
+	return run, remaining, settings
+}
+
+// Resume finishes the most recent run in the journal if it was interrupted,
+// or with app.rollback set, undoes what it did. The run is finished
+// the way it began, copying or moving with its conflict strategy,
+// whatever -copy and -on-conflict are set to.
+func (app *appEnv) Resume(ctx context.Context) (err error) {
+	if app.opts.Journal == "" {
+		return errors.New("no journal file")
+	}
+	entries, err := readJournal(app.opts.Journal)
+	if err != nil {
+		return err
+	}
+	run, remaining, settings := interruptedRun(entries)
+	if run == nil {
+		return errors.New("no interrupted run")
+	}
+	id := run[0].Run
+	// Finish the run the way it began, whatever the options to resume are
+	if settings != nil {
+		app.opts.Copy = settings.Get("mode") == actionCopy
+		if s := settings.Get("on-conflict"); s != "" {
+			app.opts.OnConflict = s
+		}
+	}
+	r, err := app.opts.runner("")
+	if err != nil {
+		return err
+	}
+	finished := actionMove
+	if app.opts.Copy {
+		finished = actionCopy
+	}
+	// Sort out the move that was in progress, which may have finished
+	// without being recorded, or left a partial copy behind.
+	var moves []Move
+	for _, m := range remaining {
+		dir := filepath.Dir(m.New)
+		_ = os.RemoveAll(filepath.Join(dir, tempName(dir, filepath.Base(m.New))))
+		oldInfo, oldErr := os.Lstat(m.Old)
+		_, newErr := os.Lstat(m.New)
+		// Copies leave the original in place, so they are done once the copy is
+		if oldErr == nil && (newErr != nil || !app.opts.Copy) {
+			if !oldInfo.IsDir() {
+				// For file hooks that match kinds
+				m.Kind = r.getKind(m.Old)
+			}
+			moves = append(moves, m)
+			continue
+		}
+		if newErr != nil {
+			app.Warn("file is missing", "old", m.Old, "new", m.New)
+			continue
+		}
+		app.Info("found finished "+finished, "old", m.Old, "new", m.New)
+		j, err := openJournal(app.opts.Journal)
+		if err != nil {
+			return err
+		}
+		j.run = id
+		err = errors.Join(j.record(finished, m.Old, m.New), j.Close())
+		if err != nil {
+			return err
+		}
+		run = append(run, journalEntry{Run: id, Action: finished, Old: m.Old, New: m.New})
+	}
+	if app.rollback {
+		if done := lastRun(run); len(done) > 0 {
+			if err = app.undo(ctx, done); err != nil {
+				return err
+			}
+		}
+		if app.dryRun {
+			return nil
+		}
+		j, err := openJournal(app.opts.Journal)
+		if err != nil {
+			return err
+		}
+		j.run = id
+		return errors.Join(j.record(actionEnd, "", ""), j.Close())
+	}
+	if app.dryRun {
+		return app.printPlan(moves)
+	}
+	app.Info("resuming run", "run", id, "moves", len(moves), "mode", finished)
+	return app.executeRun(ctx, moves, id)
+}
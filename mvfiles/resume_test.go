@@ -0,0 +1,79 @@
+package mvfiles
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResumeCopy(t *testing.T) {
+	dir := t.TempDir()
+	journal := filepath.Join(dir, "journal.csv")
+	var moves []Move
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		old := filepath.Join(dir, "in", name)
+		if err := os.MkdirAll(filepath.Dir(old), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(old, []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		moves = append(moves, Move{Old: old, New: filepath.Join(dir, "out", name)})
+	}
+
+	// Interrupt the run after the first copy
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opts := Options{Copy: true, Journal: journal, Jobs: 1}
+	opts.Progress = func(phase string, done, total int, current string) {
+		if phase == "moving" && done == 1 {
+			cancel()
+		}
+	}
+	if err := Execute(ctx, moves, opts); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want the run to be canceled", err)
+	}
+
+	// Resume without -copy
+	app := &appEnv{
+		opts:   Options{Journal: journal, Jobs: 1},
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	if err := app.Resume(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range moves {
+		for _, name := range []string{m.Old, m.New} {
+			b, err := os.ReadFile(name)
+			if err != nil {
+				t.Errorf("after resuming: %v", err)
+			} else if string(b) != filepath.Base(m.Old) {
+				t.Errorf("%s = %q, want %q", name, b, filepath.Base(m.Old))
+			}
+		}
+	}
+
+	entries, err := readJournal(journal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run, _, _ := interruptedRun(entries); run != nil {
+		t.Error("run is still interrupted after resuming")
+	}
+	copies := 0
+	for _, e := range entries {
+		switch e.Action {
+		case actionCopy:
+			copies++
+		case actionMove:
+			t.Errorf("resuming moved %s", e.Old)
+		}
+	}
+	if copies != len(moves) {
+		t.Errorf("journaled %d copies, want %d", copies, len(moves))
+	}
+}
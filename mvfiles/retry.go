@@ -0,0 +1,48 @@
+package mvfiles
+
+import (
+	"errors"
+	"syscall"
+	"time"
+)
+
+// What to do with files that have no date, for Options.OnDateError
+const (
+	DateErrorMtime = "mtime" // date them by their modification time
+	DateErrorSkip  = "skip"  // leave them in place
+	DateErrorFail  = "fail"  // stop with a *MetadataError
+)
+
+var dateErrorModes = []string{DateErrorMtime, DateErrorSkip, DateErrorFail}
+
+// Date lookups that fail with a transient error are tried dateRetries
+// more times, waiting dateRetryDelay and then twice as long each time.
+const (
+	dateRetries    = 3
+	dateRetryDelay = 50 * time.Millisecond
+)
+
+// lookupDate returns the date of path from source,
+// retrying if the lookup fails with a transient error.
+func (r *runner) lookupDate(source, path string) (time.Time, error) {
+	delay := dateRetryDelay
+	for i := 0; ; i++ {
+		t, err := r.Metadata.Date(source, path)
+		if err == nil || i == dateRetries || !isTransient(err) {
+			return t, err
+		}
+		r.Logger.Debug("retrying date", "source", source, "path", path, "error", err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// isTransient reports whether err may go away if the call is tried again,
+// as when a disk is busy or an external drive is slow to respond.
+func isTransient(err error) bool {
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return false
+	}
+	return errno.Temporary() || errno == syscall.EIO || errno == syscall.EBUSY
+}
@@ -0,0 +1,39 @@
+package mvfiles
+
+import "path/filepath"
+
+// revealRun shows the result of moves in the file manager: the file itself
+// if only one moved, and otherwise the date folder of the newest file.
+func (app *appEnv) revealRun(moves []Move) {
+	var moved []Move
+	for _, m := range moves {
+		if !m.Duplicate && !isPhotosImport(m.New) {
+			moved = append(moved, m)
+		}
+	}
+	if len(moved) == 0 {
+		return
+	}
+	if len(moved) == 1 {
+		if err := revealFile(moved[0].New); err != nil {
+			app.Warn("could not reveal", "path", moved[0].New, "error", err)
+		}
+		return
+	}
+	newest := moved[0]
+	for _, m := range moved[1:] {
+		if m.Date.After(newest.Date) {
+			newest = m
+		}
+	}
+	dir := filepath.Dir(newest.New)
+	for d := dir; filepath.Dir(d) != d; d = filepath.Dir(d) {
+		if name := filepath.Base(d); isDateDir(name) || isYearDir(name) {
+			dir = d
+			break
+		}
+	}
+	if err := openFolder(dir); err != nil {
+		app.Warn("could not open folder", "path", dir, "error", err)
+	}
+}
@@ -0,0 +1,13 @@
+package mvfiles
+
+import "os/exec"
+
+// revealFile selects the file at path in a Finder window.
+func revealFile(path string) error {
+	return exec.Command("open", "-R", path).Run()
+}
+
+// openFolder opens dir in a Finder window.
+func openFolder(dir string) error {
+	return exec.Command("open", dir).Run()
+}
@@ -0,0 +1,19 @@
+//go:build !darwin && !windows
+
+package mvfiles
+
+import (
+	"os/exec"
+	"path/filepath"
+)
+
+// revealFile opens the folder holding the file at path
+// with xdg-open, since there's no standard way to select it.
+func revealFile(path string) error {
+	return openFolder(filepath.Dir(path))
+}
+
+// openFolder opens dir in the file manager with xdg-open.
+func openFolder(dir string) error {
+	return exec.Command("xdg-open", dir).Start()
+}
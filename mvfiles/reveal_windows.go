@@ -0,0 +1,21 @@
+package mvfiles
+
+import (
+	"os/exec"
+	"path/filepath"
+)
+
+// revealFile selects the file at path in an Explorer window.
+func revealFile(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	// Explorer's exit status is meaningless, so don't wait for it
+	return exec.Command("explorer", "/select,"+abs).Start()
+}
+
+// openFolder opens dir in an Explorer window.
+func openFolder(dir string) error {
+	return exec.Command("explorer", dir).Start()
+}
@@ -0,0 +1,277 @@
+package mvfiles
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+const reviewHelp = `Commands:
+	3, 2-5, 1 4 7   toggle moves on or off
+	all, none       turn every move on or off
+	kind 3 doc      change the kind of a move
+	list            show the plan again
+	go              make the moves that are on
+	quit            stop without moving anything
+`
+
+// review shows moves and reads commands from in to toggle them on or off
+// and change their kinds, one line at a time. It returns the moves that
+// are on when the user says go, or nil if they quit.
+func (app *appEnv) review(moves []Move, in io.Reader, out io.Writer) ([]Move, error) {
+	// Moves from each of app.dirs need its own runner for their destinations.
+	// Conflicts were already logged while planning.
+	opts := app.opts
+	opts.Logger = nil
+	runners := make(map[string]*runner)
+	for _, dir := range app.dirs {
+		r, err := opts.runner(dir)
+		if err != nil {
+			return nil, err
+		}
+		runners[dir] = r
+	}
+	runnerFor := func(path string) *runner {
+		for _, dir := range app.dirs {
+			if rel, err := filepath.Rel(dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+				return runners[dir]
+			}
+		}
+		return runners[app.dirs[0]]
+	}
+	on := make([]bool, len(moves))
+	for i := range on {
+		on[i] = true
+	}
+	// Changing a kind or turning moves on or off changes which destinations
+	// are taken, so conflicts are resolved again each time from the
+	// destinations as planned. Moves that the conflict strategy skips
+	// are marked with a dash and left out.
+	planned := slices.Clone(moves)
+	moves = slices.Clone(moves)
+	skipped := make([]bool, len(moves))
+	resolve := func() error {
+		var active []Move
+		for i, m := range planned {
+			if on[i] && !m.Duplicate {
+				active = append(active, m)
+			}
+		}
+		resolved, err := runners[app.dirs[0]].resolveConflicts(active)
+		if err != nil {
+			return err
+		}
+		byOld := make(map[string]Move, len(resolved))
+		for _, m := range resolved {
+			byOld[m.Old] = m
+		}
+		for i, m := range planned {
+			moves[i], skipped[i] = m, false
+			if !on[i] || m.Duplicate {
+				continue
+			}
+			r, ok := byOld[m.Old]
+			if !ok {
+				skipped[i] = true
+				continue
+			}
+			r.renamed = r.renamed || m.renamed
+			moves[i] = r
+		}
+		return nil
+	}
+	list := func() {
+		tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		for i, m := range moves {
+			mark := " "
+			switch {
+			case skipped[i]:
+				mark = "-"
+			case on[i]:
+				mark = "x"
+			}
+			fmt.Fprintf(tw, "[%s]\t%d\t%s\t%s\t%s\n", mark, i+1, m.Old, m.New, m.Kind)
+		}
+		tw.Flush()
+	}
+	list()
+	fmt.Fprint(out, reviewHelp)
+	s := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(out, "> ")
+		if !s.Scan() {
+			return nil, s.Err()
+		}
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "go", "y", "yes":
+			var chosen []Move
+			for i, m := range moves {
+				if on[i] && !skipped[i] {
+					chosen = append(chosen, m)
+				}
+			}
+			return chosen, nil
+		case "quit", "q":
+			return nil, nil
+		case "list", "l":
+			list()
+		case "all", "none":
+			for i := range on {
+				on[i] = fields[0] == "all"
+			}
+			if err := resolve(); err != nil {
+				return nil, err
+			}
+			list()
+		case "kind", "k":
+			if len(fields) != 3 {
+				fmt.Fprintln(out, "usage: kind N KIND")
+				continue
+			}
+			i, err := strconv.Atoi(fields[1])
+			if err != nil || i < 1 || i > len(moves) {
+				fmt.Fprintf(out, "no move %q\n", fields[1])
+				continue
+			}
+			m := &planned[i-1]
+			if m.Kind == "" {
+				fmt.Fprintln(out, "directories have no kind")
+				continue
+			}
+			newpath, sanitized, err := runnerFor(m.Old).destination(m.Old, fields[2], m.Date, m.Size)
+			if err != nil {
+				fmt.Fprintln(out, err)
+				continue
+			}
+			// The new destination may or may not hold a duplicate
+			m.Kind, m.New, m.Sanitized = fields[2], newpath, sanitized
+			m.renamed, m.Duplicate = false, false
+			if err := resolve(); err != nil {
+				return nil, err
+			}
+			list()
+		case "help", "?":
+			fmt.Fprint(out, reviewHelp)
+		default:
+			nums, err := parseRanges(fields, len(moves))
+			if err != nil {
+				fmt.Fprintln(out, err)
+				continue
+			}
+			for _, n := range nums {
+				on[n-1] = !on[n-1]
+			}
+			if err := resolve(); err != nil {
+				return nil, err
+			}
+			list()
+		}
+	}
+}
+
+// parseRanges parses numbers and ranges like "2-5" between 1 and count.
+func parseRanges(fields []string, count int) ([]int, error) {
+	var nums []int
+	for _, f := range fields {
+		lo, hi, isRange := strings.Cut(f, "-")
+		start, err := strconv.Atoi(lo)
+		if err != nil {
+			return nil, fmt.Errorf("unknown command %q (type help for a list)", f)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(hi); err != nil {
+				return nil, fmt.Errorf("bad range %q", f)
+			}
+		}
+		if start < 1 || end > count || start > end {
+			return nil, fmt.Errorf("no moves %q", f)
+		}
+		for n := start; n <= end; n++ {
+			nums = append(nums, n)
+		}
+	}
+	return nums, nil
+}
+
+// summarize describes what executing moves will do, like "Will move 214 files
+// (1.8 GB) into 9 folders; 3 conflicts will be renamed".
+func (app *appEnv) summarize(moves []Move) string {
+	var (
+		files, renamed, replaced, dups int
+		size                           int64
+	)
+	folders := make(map[string]bool)
+	for _, m := range moves {
+		if m.Duplicate {
+			dups++
+			continue
+		}
+		files++
+		size += m.Size
+		folders[filepath.Dir(m.New)] = true
+		if m.renamed {
+			renamed++
+		} else if _, err := os.Lstat(m.New); err == nil {
+			replaced++
+		}
+	}
+	verb := "move"
+	if app.opts.Copy {
+		verb = "copy"
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Will %s %s (%s) into %s",
+		verb, plural(files, "file"), formatSize(size), plural(len(folders), "folder"))
+	if renamed > 0 {
+		fmt.Fprintf(&sb, "; %s will be renamed", plural(renamed, "conflict"))
+	}
+	if replaced > 0 {
+		action := "overwritten"
+		if app.opts.OnConflict == ConflictTrash {
+			action = "moved to the Trash"
+		}
+		fmt.Fprintf(&sb, "; %s will be %s", plural(replaced, "existing file"), action)
+	}
+	if dups > 0 {
+		action := "left in place"
+		if app.opts.DedupeTrash {
+			action = "moved to the Trash"
+		}
+		fmt.Fprintf(&sb, "; %s will be %s", plural(dups, "duplicate"), action)
+	}
+	return sb.String()
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// confirmMoves prints a summary of moves to out and asks whether to go ahead,
+// returning true only if the answer read from in is yes.
+func (app *appEnv) confirmMoves(moves []Move, in io.Reader, out io.Writer) (bool, error) {
+	fmt.Fprintln(out, app.summarize(moves))
+	fmt.Fprint(out, "Continue? [y/N] ")
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,69 @@
+package mvfiles
+
+import (
+	"fmt"
+	"strings"
+)
+
+// illegalChars can't be used in names on Windows, exFAT, FAT, and SMB volumes.
+const illegalChars = `"*/:<>?\|`
+
+// reservedNames can't be used as file names on Windows, with or without an extension.
+var reservedNames = []string{
+	"CON", "PRN", "AUX", "NUL",
+	"COM1", "COM2", "COM3", "COM4", "COM5", "COM6", "COM7", "COM8", "COM9",
+	"LPT1", "LPT2", "LPT3", "LPT4", "LPT5", "LPT6", "LPT7", "LPT8", "LPT9",
+}
+
+func isIllegalRune(r rune) bool {
+	return r < ' ' || r == 0x7f || strings.ContainsRune(illegalChars, r)
+}
+
+// checkSanitize returns an error if repl can't be put in place of illegal characters.
+func checkSanitize(repl string) error {
+	if strings.ContainsFunc(repl, isIllegalRune) || strings.TrimRight(repl, ". ") != repl {
+		return fmt.Errorf("bad sanitize replacement %q", repl)
+	}
+	return nil
+}
+
+// sanitizeName returns name with repl in place of the characters
+// that other file systems don't allow, added to reserved names,
+// and in place of trailing dots and spaces, which Windows drops.
+func sanitizeName(name, repl string) string {
+	var sb strings.Builder
+	for _, r := range name {
+		if isIllegalRune(r) {
+			sb.WriteString(repl)
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	name = sb.String()
+	base, ext := splitExt(name)
+	for _, reserved := range reservedNames {
+		if strings.EqualFold(base, reserved) {
+			return base + repl + ext
+		}
+	}
+	if trimmed := strings.TrimRight(name, ". "); trimmed != name {
+		return trimmed + repl
+	}
+	return name
+}
+
+// sanitizePath applies sanitizeName to each of the slash separated
+// elements of path and reports whether any changed.
+func sanitizePath(path, repl string) (string, bool) {
+	elems := strings.Split(path, "/")
+	changed := false
+	for i, elem := range elems {
+		if elem == "" {
+			continue
+		}
+		if elems[i] = sanitizeName(elem, repl); elems[i] != elem {
+			changed = true
+		}
+	}
+	return strings.Join(elems, "/"), changed
+}
@@ -0,0 +1,238 @@
+package mvfiles
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/carlmjohnson/versioninfo"
+)
+
+// latestReleaseURL is the GitHub API endpoint for the newest release.
+const latestReleaseURL = "https://api.github.com/repos/earthboundkid/scooter/releases/latest"
+
+// checksumsAsset is the release asset listing the SHA-256 of the other assets
+// in the format of sha256sum.
+const checksumsAsset = "checksums.txt"
+
+type release struct {
+	Tag    string `json:"tag_name"`
+	Assets []struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+// assetURL returns the download URL of the asset called name.
+func (rel *release) assetURL(name string) (string, error) {
+	for _, a := range rel.Assets {
+		if a.Name == name {
+			return a.URL, nil
+		}
+	}
+	return "", fmt.Errorf("release %s has no %s", rel.Tag, name)
+}
+
+// binaryAsset is the name of the release asset for this platform,
+// like scooter-darwin-arm64.
+func binaryAsset() string {
+	name := "scooter-" + runtime.GOOS + "-" + runtime.GOARCH
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// SelfUpdate replaces the running executable with the binary from
+// the latest release on GitHub if it is newer than versioninfo.Version,
+// after checking it against the release's checksums. The checksums only
+// catch corrupted downloads: they come from the same release as the binary
+// and aren't signed, so they don't prove who published it.
+// The release workflow in .github/workflows publishes the assets.
+// With app.check, it only reports whether there is an update.
+func (app *appEnv) SelfUpdate(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	var rel release
+	if err := fetchJSON(ctx, latestReleaseURL, &rel); err != nil {
+		return fmt.Errorf("checking for updates: %w", err)
+	}
+	current := versioninfo.Version
+	if !newerVersion(rel.Tag, current) && !app.force {
+		fmt.Printf("scooter %s is up to date\n", current)
+		return nil
+	}
+	if app.check {
+		fmt.Printf("scooter %s is available (you have %s)\n", rel.Tag, current)
+		return nil
+	}
+	binURL, err := rel.assetURL(binaryAsset())
+	if err != nil {
+		return err
+	}
+	sumsURL, err := rel.assetURL(checksumsAsset)
+	if err != nil {
+		return err
+	}
+	sums, err := fetch(ctx, sumsURL)
+	if err != nil {
+		return fmt.Errorf("downloading checksums: %w", err)
+	}
+	want, err := findChecksum(sums, binaryAsset())
+	if err != nil {
+		return err
+	}
+	bin, err := fetch(ctx, binURL)
+	if err != nil {
+		return fmt.Errorf("downloading %s: %w", binaryAsset(), err)
+	}
+	if got := sha256.Sum256(bin); hex.EncodeToString(got[:]) != want {
+		return fmt.Errorf("checksum mismatch for %s: got %x, want %s", binaryAsset(), got, want)
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return err
+	}
+	if err = replaceExecutable(exe, bin); err != nil {
+		return fmt.Errorf("replacing %q: %w", exe, err)
+	}
+	app.Info("updated", "from", current, "to", rel.Tag, "path", exe)
+	fmt.Printf("Updated scooter from %s to %s\n", current, rel.Tag)
+	return nil
+}
+
+func fetch(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("unexpected status %s", res.Status)
+	}
+	return io.ReadAll(res.Body)
+}
+
+func fetchJSON(ctx context.Context, url string, v any) error {
+	b, err := fetch(ctx, url)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// findChecksum returns the hex encoded checksum of name
+// in sums, a file in the format of sha256sum.
+func findChecksum(sums []byte, name string) (string, error) {
+	s := bufio.NewScanner(bytes.NewReader(sums))
+	for s.Scan() {
+		sum, file, ok := strings.Cut(s.Text(), " ")
+		if ok && strings.TrimLeft(strings.TrimSpace(file), "*") == name {
+			return strings.ToLower(sum), nil
+		}
+	}
+	if err := s.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("no checksum for %s", name)
+}
+
+// replaceExecutable writes bin next to exe and renames it over exe,
+// so that exe is never left half written. On Windows, the running exe
+// is first renamed to exe.old, and renamed back if the new one fails to move in.
+func replaceExecutable(exe string, bin []byte) error {
+	info, err := os.Stat(exe)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(exe), ".scooter-update-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	_, err = f.Write(bin)
+	err = errors.Join(err, f.Close(), os.Chmod(tmp, info.Mode().Perm()))
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	old := ""
+	if runtime.GOOS == "windows" {
+		// A running executable can't be replaced, but it can be renamed
+		old = exe + ".old"
+		_ = os.Remove(old)
+		if err = os.Rename(exe, old); err != nil {
+			_ = os.Remove(tmp)
+			return err
+		}
+	}
+	if err = os.Rename(tmp, exe); err != nil {
+		_ = os.Remove(tmp)
+		// Put the running executable back so scooter is still installed
+		if old != "" {
+			if rerr := os.Rename(old, exe); rerr != nil {
+				err = errors.Join(err, fmt.Errorf("restoring %s: %w", exe, rerr))
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// newerVersion reports whether the release tagged tag
+// is newer than the version current, both like v1.2.3.
+// Development builds, whose versions aren't like that, are never
+// older than a release.
+func newerVersion(tag, current string) bool {
+	t, ok := parseVersion(tag)
+	if !ok {
+		return false
+	}
+	c, ok := parseVersion(current)
+	if !ok {
+		return false
+	}
+	for i := range t {
+		if t[i] != c[i] {
+			return t[i] > c[i]
+		}
+	}
+	return false
+}
+
+// parseVersion parses a version like v1.2.3.
+// Pre-release and build suffixes, like v1.2.3-rc1, aren't accepted.
+func parseVersion(v string) (nums [3]int, ok bool) {
+	parts := strings.Split(strings.TrimPrefix(v, "v"), ".")
+	if len(parts) != 3 {
+		return nums, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nums, false
+		}
+		nums[i] = n
+	}
+	return nums, true
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package mvfiles
+
+import (
+	"context"
+	"os/exec"
+)
+
+// shellCommand returns a command running command in sh
+// with arg as its last argument.
+func shellCommand(ctx context.Context, command, arg string) *exec.Cmd {
+	return exec.CommandContext(ctx, "sh", "-c", command+` "$@"`, "sh", arg)
+}
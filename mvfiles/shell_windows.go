@@ -0,0 +1,20 @@
+package mvfiles
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+)
+
+// shellCommand returns a command running command in cmd.exe
+// with the path arg quoted as its last argument.
+// cmd.exe doesn't parse the escaping exec uses for arguments,
+// so the command line is written out here instead.
+func shellCommand(ctx context.Context, command, arg string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "cmd.exe")
+	// With /S, cmd.exe removes the outer quotes and leaves the rest alone
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CmdLine: `cmd.exe /S /C "` + command + ` "` + arg + `""`,
+	}
+	return cmd
+}
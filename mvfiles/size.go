@@ -0,0 +1,105 @@
+package mvfiles
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SizeRoute sends files of at least MinSize bytes to Dir
+// instead of the usual root for their kind.
+type SizeRoute struct {
+	Kind    string // empty for files of any kind
+	MinSize int64
+	// Dir is the root for the files. If it is relative,
+	// it is a folder in the usual root, like "large".
+	Dir string
+}
+
+// ParseSizeRoute parses a size route written as [kind]>size=directory,
+// like ">2GB=large" or "video>500MB=/Volumes/Archive/Video".
+func ParseSizeRoute(s string) (SizeRoute, error) {
+	rule, dir, ok := strings.Cut(s, "=")
+	kind, size, ok2 := strings.Cut(rule, ">")
+	if !ok || !ok2 || dir == "" {
+		return SizeRoute{}, fmt.Errorf("bad size route %q: must be [kind]>size=directory", s)
+	}
+	n, err := parseSize(size)
+	if err != nil {
+		return SizeRoute{}, fmt.Errorf("bad size route %q: %w", s, err)
+	}
+	return SizeRoute{strings.TrimSpace(kind), n, strings.TrimSpace(dir)}, nil
+}
+
+// parseSize parses a size in bytes with an optional decimal unit, like 1.5GB.
+func parseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	num := strings.TrimRight(strings.TrimSuffix(s, "B"), "KMGT")
+	mult := 1.0
+	switch strings.TrimSuffix(strings.TrimPrefix(s, num), "B") {
+	case "":
+	case "K":
+		mult = 1e3
+	case "M":
+		mult = 1e6
+	case "G":
+		mult = 1e9
+	case "T":
+		mult = 1e12
+	default:
+		return 0, fmt.Errorf("bad size %q", s)
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(num), 64)
+	if err != nil || n < 0 || math.IsInf(n, 0) {
+		return 0, fmt.Errorf("bad size %q", s)
+	}
+	return int64(n * mult), nil
+}
+
+// sortSizeRoutes puts routes for a kind before routes for any kind,
+// and then routes for bigger files first, so the first match is the most specific.
+func sortSizeRoutes(routes []SizeRoute) {
+	slices.SortStableFunc(routes, func(a, b SizeRoute) int {
+		if (a.Kind == "") != (b.Kind == "") {
+			if a.Kind == "" {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Compare(b.MinSize, a.MinSize)
+	})
+}
+
+// sizeRoute returns the directory of the size route for a file of kind and size,
+// if any matches.
+func (r *runner) sizeRoute(kind string, size int64) (string, bool) {
+	for _, sr := range r.sizeRoutes {
+		if (sr.Kind == "" || sr.Kind == kind) && size >= sr.MinSize {
+			return sr.Dir, true
+		}
+	}
+	return "", false
+}
+
+// isBucket reports whether name is the top folder of a relative size route,
+// like "large", which is filled by organizing.
+func (r *runner) isBucket(name string) bool {
+	return slices.ContainsFunc(r.sizeRoutes, func(sr SizeRoute) bool {
+		dir := filepath.ToSlash(filepath.Clean(sr.Dir))
+		if filepath.IsAbs(sr.Dir) || strings.HasPrefix(sr.Dir, "~") {
+			return false
+		}
+		top, _, _ := strings.Cut(dir, "/")
+		return top == name
+	})
+}
+
+// isArchiveDir reports whether name is a folder at the top of a directory
+// that organizing fills: a year folder or a size route bucket.
+func (r *runner) isArchiveDir(name string) bool {
+	return isYearDir(name) || r.isBucket(name)
+}
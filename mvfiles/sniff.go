@@ -0,0 +1,102 @@
+package mvfiles
+
+import (
+	"io"
+	"net/http"
+	"strings"
+)
+
+// sniffedExts maps the content types recognized by http.DetectContentType
+// to extensions that can be looked up in Kinds.
+var sniffedExts = map[string]string{
+	"application/ogg":              "ogg",
+	"application/pdf":              "pdf",
+	"application/postscript":       "ps",
+	"application/vnd.rar":          "rar",
+	"application/wasm":             "wasm",
+	"application/x-gzip":           "gz",
+	"application/x-rar-compressed": "rar",
+	"application/zip":              "zip",
+	"audio/aiff":                   "aiff",
+	"audio/midi":                   "mid",
+	"audio/mpeg":                   "mp3",
+	"audio/wave":                   "wav",
+	"font/otf":                     "otf",
+	"font/ttf":                     "ttf",
+	"font/woff":                    "woff",
+	"font/woff2":                   "woff2",
+	"image/bmp":                    "bmp",
+	"image/gif":                    "gif",
+	"image/jpeg":                   "jpg",
+	"image/png":                    "png",
+	"image/webp":                   "webp",
+	"image/x-icon":                 "ico",
+	"text/html":                    "html",
+	"text/plain":                   "txt",
+	"text/xml":                     "xml",
+	"video/avi":                    "avi",
+	"video/mp4":                    "mp4",
+	"video/webm":                   "webm",
+}
+
+// sniffExt returns the usual extension for the contents of the file name
+// in src, or the empty string if they are not recognized.
+func sniffExt(src FileSource, name string) (string, error) {
+	f, err := src.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	if n == 0 {
+		return "", nil
+	}
+	contentType, _, _ := strings.Cut(http.DetectContentType(buf[:n]), ";")
+	return sniffedExts[contentType], nil
+}
+
+// getKind returns the kind of the file at path. Screenshots get their own kind.
+// With ClassifyUTI, the kind comes from the system's content type for the file
+// if it has one. Otherwise, it comes from the extension, or if the extension
+// is unknown and r.Sniff is set, from the file's contents.
+// Packages with an unknown extension are of kind package.
+func (r *runner) getKind(path string) string {
+	kind := r.classify(path)
+	if shot := r.Kinds.screenshotKind(r.Source, path, kind); shot != "" {
+		return shot
+	}
+	return kind
+}
+
+func (r *runner) classify(path string) string {
+	if r.Classify == ClassifyUTI {
+		kind, err := getUTIKind(path)
+		if err != nil {
+			r.Logger.Debug("classifying", "path", path, "error", err)
+		}
+		if kind != "" {
+			return kind
+		}
+	}
+	kind := r.Kinds.Kind(path)
+	if kind == r.Kinds.fallback && isPackage(path) {
+		return packageKind
+	}
+	if !r.Sniff || kind != r.Kinds.fallback {
+		return kind
+	}
+	ext, err := sniffExt(r.Source, path)
+	if err != nil {
+		r.Logger.Debug("sniffing", "path", path, "error", err)
+		return kind
+	}
+	if sniffed, ok := r.Kinds.exts[ext]; ok {
+		r.Logger.Debug("sniffed", "path", path, "kind", sniffed)
+		return sniffed
+	}
+	return kind
+}
@@ -0,0 +1,65 @@
+package mvfiles
+
+import (
+	"net/url"
+	"strings"
+)
+
+// getSource returns the domain that the file at path in src was downloaded
+// from, like "github.com", using the URLs macOS records in kMDItemWhereFroms.
+// It returns the empty string if the file has no such record.
+func getSource(src FileSource, path string) string {
+	value, err := src.Xattr(path, "com.apple.metadata:kMDItemWhereFroms")
+	if err != nil {
+		return ""
+	}
+	urls, err := parseBPlistStrings(value)
+	if err != nil {
+		return ""
+	}
+	// The first URL is the download and the second is the page linking to it
+	for _, s := range urls {
+		u, err := url.Parse(s)
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	}
+	return ""
+}
+
+// quarantineApps maps the agents recorded in quarantines
+// to the names of the apps they stand for.
+var quarantineApps = map[string]string{
+	"sharingd":       "AirDrop",
+	"com.apple.mail": "Mail",
+}
+
+// getApp returns the name of the application that the file at path in src
+// came from, like "Safari", "Mail", or "AirDrop", using the quarantine
+// Launch Services puts on downloads and the URLs in kMDItemWhereFroms.
+// It returns the empty string if the application isn't known.
+func getApp(src FileSource, path string) string {
+	if fields := quarantine(src, path); len(fields) > 2 && fields[2] != "" {
+		agent := fields[2]
+		if app, ok := quarantineApps[agent]; ok {
+			return app
+		}
+		return agent
+	}
+	value, err := src.Xattr(path, "com.apple.metadata:kMDItemWhereFroms")
+	if err != nil {
+		return ""
+	}
+	urls, err := parseBPlistStrings(value)
+	if err != nil {
+		return ""
+	}
+	// Mail records the message an attachment was saved from
+	for _, s := range urls {
+		if strings.HasPrefix(s, "message:") {
+			return "Mail"
+		}
+	}
+	return ""
+}
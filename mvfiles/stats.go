@@ -0,0 +1,100 @@
+package mvfiles
+
+import (
+	"cmp"
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Stat totals a group of files.
+type Stat struct {
+	Name  string `json:"name"`
+	Files int    `json:"files"`
+	Bytes int64  `json:"bytes"`
+}
+
+// Stats is the breakdown of an organized tree.
+type Stats struct {
+	// Kinds are sorted from the most bytes to the least.
+	Kinds []Stat `json:"kinds"`
+	// Months are named like 2024-03 and sorted by date.
+	Months []Stat `json:"months"`
+}
+
+// GetStats totals the files in dir and its subdirectories by kind
+// and by the year and month of their dates.
+func GetStats(dir string, opts Options) (*Stats, error) {
+	r, err := opts.runner(dir)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() && path != dir && isPackage(path) {
+			paths = append(paths, path)
+			return fs.SkipDir
+		}
+		if d.Type().IsRegular() {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	files, err := r.buildMoves(paths, nil)
+	if err != nil {
+		return nil, err
+	}
+	kinds := make(map[string]*Stat)
+	months := make(map[string]*Stat)
+	add := func(m map[string]*Stat, name string, size int64) {
+		s := m[name]
+		if s == nil {
+			s = &Stat{Name: name}
+			m[name] = s
+		}
+		s.Files++
+		s.Bytes += size
+	}
+	for _, f := range files {
+		add(kinds, f.Kind, f.Size)
+		add(months, formatDate(f.Date, "2006-01"), f.Size)
+	}
+	stats := &Stats{Kinds: []Stat{}, Months: []Stat{}}
+	for _, s := range kinds {
+		stats.Kinds = append(stats.Kinds, *s)
+	}
+	slices.SortFunc(stats.Kinds, func(a, b Stat) int {
+		return cmp.Or(cmp.Compare(b.Bytes, a.Bytes), cmp.Compare(a.Name, b.Name))
+	})
+	for _, s := range months {
+		stats.Months = append(stats.Months, *s)
+	}
+	slices.SortFunc(stats.Months, func(a, b Stat) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return stats, nil
+}
+
+// Stats prints the totals for app.dir.
+func (app *appEnv) Stats(ctx context.Context) error {
+	stats, err := GetStats(app.dir, app.opts)
+	if err != nil {
+		return err
+	}
+	return writeStats(os.Stdout, app.format, stats)
+}
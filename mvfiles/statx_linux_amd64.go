@@ -0,0 +1,3 @@
+package mvfiles
+
+const sysStatx = 332 // SYS_statx
@@ -0,0 +1,5 @@
+//go:build linux && (arm64 || loong64 || riscv64)
+
+package mvfiles
+
+const sysStatx = 291 // SYS_statx in the generic syscall table
@@ -0,0 +1,5 @@
+//go:build linux && !(amd64 || arm64 || loong64 || riscv64)
+
+package mvfiles
+
+const sysStatx = 0 // not looked up for this architecture
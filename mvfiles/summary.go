@@ -0,0 +1,78 @@
+package mvfiles
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+)
+
+// runSummary is the JSON written to -summary-file and posted to -webhook
+// after a run. Only the webhook lists the moves.
+type runSummary struct {
+	// Dir is the first of Dirs, for readers written before there could be several
+	Dir      string   `json:"dir"`
+	Dirs     []string `json:"dirs"`
+	Planned  int      `json:"planned"`
+	Moved    int      `json:"moved"`
+	Skipped  int      `json:"skipped"`
+	Failed   int      `json:"failed"`
+	Bytes    int64    `json:"bytes"`
+	Duration float64  `json:"duration_seconds"`
+	Journal  string   `json:"journal,omitempty"`
+	Error    string   `json:"error,omitempty"`
+	Moves    []Move   `json:"moves,omitempty"`
+}
+
+func (app *appEnv) newRunSummary(moves []Move, err error) runSummary {
+	sum := runSummary{
+		Dir:      app.dir,
+		Dirs:     app.dirs,
+		Planned:  len(moves),
+		Duration: time.Since(app.start).Round(time.Millisecond).Seconds(),
+		Journal:  app.opts.Journal,
+	}
+	failed := make(map[string]bool)
+	var (
+		fme *FailedMovesError
+		fm  *FailedMove
+	)
+	switch {
+	case errors.As(err, &fme):
+		for _, f := range fme.Failures {
+			failed[f.Old] = true
+		}
+	case errors.As(err, &fm):
+		failed[fm.Old] = true
+	}
+	if err != nil {
+		sum.Error = err.Error()
+	}
+	for _, m := range moves {
+		switch {
+		case failed[m.Old]:
+			sum.Failed++
+		case app.skipped[m.Old]:
+			sum.Skipped++
+		case app.moved[m.Old]:
+			sum.Moved++
+			sum.Bytes += m.Size
+		}
+	}
+	return sum
+}
+
+// writeSummary writes the summary of a run to app.summaryFile,
+// or standard output if it is "-".
+func (app *appEnv) writeSummary(moves []Move, err error) error {
+	b, jerr := json.MarshalIndent(app.newRunSummary(moves, err), "", "  ")
+	if jerr != nil {
+		return jerr
+	}
+	b = append(b, '\n')
+	if app.summaryFile == "-" {
+		_, err = os.Stdout.Write(b)
+		return err
+	}
+	return os.WriteFile(app.summaryFile, b, 0o644)
+}
@@ -0,0 +1,72 @@
+package mvfiles
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// leaveSymlink replaces the item moved from oldpath to newpath with a link to it,
+// for apps that still refer to the old path.
+func (r *runner) leaveSymlink(j *journal, oldpath, newpath string) error {
+	target, err := filepath.Abs(newpath)
+	if err != nil {
+		return err
+	}
+	if err = os.Symlink(target, oldpath); err != nil {
+		return err
+	}
+	return j.record(actionLink, "", oldpath)
+}
+
+// isLeftLink reports whether path in src is a link left by leaveSymlink,
+// which points to an item whose recorded origin is path.
+func isLeftLink(src FileSource, path string) bool {
+	target, err := src.Readlink(path)
+	if err != nil {
+		return false
+	}
+	old, err := src.Xattr(target, originPathXattr)
+	if err != nil {
+		return false
+	}
+	abs, err := filepath.Abs(path)
+	return err == nil && string(old) == abs
+}
+
+// CleanupLinks removes the links left in dir and its subdirectories
+// by Options.LeaveSymlink that are older than age and returns their paths.
+// Like scanning for files to move, it doesn't look inside hidden folders
+// or packages. If dryRun is set, CleanupLinks only returns the links
+// it would remove.
+func CleanupLinks(dir string, age time.Duration, dryRun bool) ([]string, error) {
+	var removed []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && (strings.HasPrefix(d.Name(), ".") || isPackage(path)) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.Type()&fs.ModeSymlink == 0 || !isLeftLink(osFiles{}, path) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || time.Since(info.ModTime()) < age {
+			return nil
+		}
+		if !dryRun {
+			if err = os.Remove(path); err != nil {
+				return err
+			}
+		}
+		removed = append(removed, path)
+		return nil
+	})
+	return removed, err
+}
@@ -0,0 +1,172 @@
+package mvfiles
+
+import (
+	"cmp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Sync clients recognized by syncClient
+const (
+	syncDropbox   = "Dropbox"
+	syncSyncthing = "Syncthing"
+	syncOneDrive  = "OneDrive"
+)
+
+const (
+	// syncThrottle is the pause between moves with Options.SyncSafe
+	// when Options.Throttle is not set.
+	syncThrottle = 250 * time.Millisecond
+	// syncSettle is how long a file in a sync folder must go unmodified
+	// before it counts as synced.
+	syncSettle = 10 * time.Second
+	// syncTimeout is how long to wait for a file to finish syncing.
+	syncTimeout = time.Minute
+	// syncWarnMoves is how many moves into or out of a sync folder
+	// it takes to suggest Options.SyncSafe.
+	syncWarnMoves = 100
+)
+
+// syncMarkers are the files that sync clients keep at the root of their folders.
+var syncMarkers = map[string]string{
+	".dropbox":  syncDropbox,
+	".stfolder": syncSyncthing,
+}
+
+// syncClient returns the name of the sync client whose folder dir is in
+// or the empty string if it isn't in one.
+func syncClient(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	// File provider folders on macOS, like ~/Library/CloudStorage/GoogleDrive-me@example.com
+	if _, rest, ok := strings.Cut(filepath.ToSlash(dir), "/Library/CloudStorage/"); ok {
+		name, _, _ := strings.Cut(rest, "/")
+		name, _, _ = strings.Cut(name, "-")
+		return name
+	}
+	for _, env := range []string{"OneDrive", "OneDriveConsumer", "OneDriveCommercial"} {
+		if root := os.Getenv(env); root != "" && isWithin(root, dir) {
+			return syncOneDrive
+		}
+	}
+	for {
+		for marker, client := range syncMarkers {
+			if _, err := os.Lstat(filepath.Join(dir, marker)); err == nil {
+				return client
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// isWithin reports whether path is root or inside of it.
+func isWithin(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && filepath.IsLocal(rel)
+}
+
+// tempName returns the name for a temporary file in dir
+// based on name that the sync client dir is in leaves alone,
+// so that it isn't uploaded only to be renamed a moment later.
+func tempName(dir, name string) string {
+	switch syncClient(dir) {
+	case "":
+		return ".scooter-" + name
+	case syncSyncthing:
+		return ".syncthing." + name + ".tmp"
+	default:
+		// Dropbox, OneDrive, and Google Drive skip Office's lock files
+		return "~$scooter-" + name + ".tmp"
+	}
+}
+
+// syncClientOf returns the sync client for the folder that path is in,
+// remembering it for other paths in the same folder.
+func (r *runner) syncClientOf(path string) string {
+	dir := filepath.Dir(path)
+	client, ok := r.syncClients[dir]
+	if !ok {
+		client = syncClient(dir)
+		if r.syncClients == nil {
+			r.syncClients = make(map[string]string)
+		}
+		r.syncClients[dir] = client
+	}
+	return client
+}
+
+// checkSync looks for moves into or out of sync folders.
+// With r.SyncSafe, they are throttled by syncThrottle if nothing else is set.
+// Otherwise, it suggests r.SyncSafe if there are a lot of them.
+// Only moves on the real file system are checked.
+func (r *runner) checkSync(moves []Move) {
+	if _, ok := r.Mover.(osMover); !ok {
+		return
+	}
+	n := 0
+	client := ""
+	for _, m := range moves {
+		c := cmp.Or(r.syncClientOf(m.Old), r.syncClientOf(m.New))
+		if c != "" {
+			n++
+			client = c
+		}
+	}
+	if n == 0 {
+		return
+	}
+	if r.SyncSafe {
+		if r.Throttle == 0 {
+			r.Throttle = syncThrottle
+		}
+		return
+	}
+	if n >= syncWarnMoves {
+		r.Logger.Warn("moving many files in a sync folder; use -sync-safe to avoid sync storms and conflict copies", "client", client, "moves", n)
+	}
+}
+
+// waitSynced waits up to syncTimeout for the file at path,
+// which is in the folder of a sync client, to finish syncing.
+// It reports whether the file is synced.
+func (r *runner) waitSynced(path string) bool {
+	deadline := time.Now().Add(syncTimeout)
+	for {
+		if r.isSynced(path) {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// isSynced reports whether the file at path looks to be done syncing:
+// it has been downloaded, no sync client has a temporary file for it,
+// and it hasn't been modified for syncSettle.
+func (r *runner) isSynced(path string) bool {
+	info, err := os.Lstat(path)
+	if err != nil {
+		// Reported when the move is made
+		return true
+	}
+	if isDataless(path) || r.Now().Sub(info.ModTime()) < syncSettle {
+		return false
+	}
+	dir, name := filepath.Split(path)
+	for _, tmp := range []string{".syncthing." + name + ".tmp", "~syncthing~" + name + ".tmp"} {
+		if _, err := os.Lstat(filepath.Join(dir, tmp)); err == nil {
+			return false
+		}
+	}
+	return true
+}
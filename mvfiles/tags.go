@@ -0,0 +1,9 @@
+package mvfiles
+
+// tagColors are the Finder's label colors, numbered as in its tags.
+var tagColors = map[string]int{
+	"none": 0, "gray": 1, "green": 2, "purple": 3,
+	"blue": 4, "yellow": 5, "red": 6, "orange": 7,
+}
+
+var tagColorNames = []string{"none", "gray", "green", "purple", "blue", "yellow", "red", "orange"}
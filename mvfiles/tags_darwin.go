@@ -0,0 +1,27 @@
+package mvfiles
+
+import (
+	"fmt"
+	"strings"
+)
+
+const tagsXattr = "com.apple.metadata:_kMDItemUserTags"
+
+// tagFile adds the Finder tag with the name and color to the file at path.
+// Each tag is stored as its name, followed by a newline and its color
+// if it has one.
+func tagFile(path, tag string, color int) error {
+	var tags []string
+	if value, err := getXattr(path, tagsXattr); err == nil {
+		tags, _ = parseBPlistStrings(value)
+	}
+	for _, t := range tags {
+		if name, _, _ := strings.Cut(t, "\n"); name == tag {
+			return nil
+		}
+	}
+	if color != 0 {
+		tag = fmt.Sprintf("%s\n%d", tag, color)
+	}
+	return setXattr(path, tagsXattr, encodeBPlistStrings(append(tags, tag)))
+}
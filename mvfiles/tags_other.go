@@ -0,0 +1,23 @@
+//go:build !darwin
+
+package mvfiles
+
+import (
+	"slices"
+	"strings"
+)
+
+// tagsXattr holds comma separated tags on freedesktop systems.
+const tagsXattr = "user.xdg.tags"
+
+// tagFile adds the tag to the file at path. There is no standard for colors.
+func tagFile(path, tag string, color int) error {
+	var tags []string
+	if value, err := getXattr(path, tagsXattr); err == nil && len(value) > 0 {
+		tags = strings.Split(string(value), ",")
+	}
+	if slices.Contains(tags, tag) {
+		return nil
+	}
+	return setXattr(path, tagsXattr, []byte(strings.Join(append(tags, tag), ",")))
+}
@@ -0,0 +1,109 @@
+package mvfiles
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// templateData holds the variables available to -template.
+type templateData struct {
+	Date  time.Time
+	Year  string
+	Month string
+	Day   string
+	// MonthName is the name of the month in the user's language
+	MonthName string
+	Week      string // ISO week, like W18
+	WeekYear  string // year of the ISO week
+	Quarter   string // like Q2
+	Kind      string // empty for directories
+	Ext       string // lowercase, without the leading dot, like tar.gz
+	Name      string
+	Base      string // Name without its extension
+	Source    string // domain the file was downloaded from, if known
+	App       string // application the file came from, like Safari or AirDrop, if known
+}
+
+// templateFuncs are the functions available to templates.
+var templateFuncs = template.FuncMap{
+	"lower":   strings.ToLower,
+	"upper":   strings.ToUpper,
+	"replace": func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"slug":    slugify,
+}
+
+// slugify lowercases s and replaces runs of spaces and underscores with a hyphen.
+func slugify(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return r == ' ' || r == '_' || r == '-'
+	}), "-")
+}
+
+func newTemplateData(name, kind string, date time.Time) templateData {
+	weekYear, week := date.ISOWeek()
+	base, ext := splitExt(name)
+	return templateData{
+		Date:      date,
+		Year:      fmt.Sprintf("%d", date.Year()),
+		Month:     fmt.Sprintf("%02d", date.Month()),
+		Day:       fmt.Sprintf("%02d", date.Day()),
+		MonthName: monthName(date.Month()),
+		Week:      fmt.Sprintf("W%02d", week),
+		WeekYear:  fmt.Sprintf("%d", weekYear),
+		Quarter:   fmt.Sprintf("Q%d", (date.Month()+2)/3),
+		Kind:      kind,
+		Ext:       strings.ToLower(strings.TrimPrefix(ext, ".")),
+		Name:      name,
+		Base:      base,
+	}
+}
+
+func parseTemplate(s string) (*template.Template, error) {
+	return template.New("template").Option("missingkey=error").Funcs(templateFuncs).Parse(s)
+}
+
+// execRenameTemplate returns the new name for the file in data.
+func execRenameTemplate(t *template.Template, data templateData) (string, error) {
+	var sb strings.Builder
+	if err := t.Execute(&sb, data); err != nil {
+		return "", err
+	}
+	name := strings.TrimSpace(sb.String())
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("bad name %q from rename template for %q", name, data.Name)
+	}
+	return safeName(name), nil
+}
+
+// execTemplate returns the slash separated folder for data.
+// Empty path segments, as with the Kind of a directory, are dropped,
+// and segments that are not valid names on this platform are changed.
+func execTemplate(t *template.Template, data templateData) (string, error) {
+	var sb strings.Builder
+	if err := t.Execute(&sb, data); err != nil {
+		return "", err
+	}
+	dir := path.Clean("/" + filepath.ToSlash(sb.String()))
+	segments := strings.Split(strings.TrimPrefix(dir, "/"), "/")
+	for i, seg := range segments {
+		if seg != "" {
+			segments[i] = safeName(seg)
+		}
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+// splitExt splits name into its base and its extension with the leading dot,
+// keeping compound extensions like .tar.gz together.
+func splitExt(name string) (base, ext string) {
+	ext = filepath.Ext(name)
+	base = strings.TrimSuffix(name, ext)
+	if inner := filepath.Ext(base); strings.EqualFold(inner, ".tar") && inner != filepath.Base(base) {
+		return strings.TrimSuffix(base, inner), inner + ext
+	}
+	return base, ext
+}
@@ -0,0 +1,95 @@
+package mvfiles
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPlanTemplates(t *testing.T) {
+	date := time.Date(2025, 5, 2, 15, 4, 5, 0, time.UTC)
+	for _, tc := range []struct {
+		name string
+		set  func(*Options)
+		want map[string]string
+	}{
+		{"default", func(*Options) {}, map[string]string{
+			"/in/Report.PDF": "/in/2025/05/doc/Report.PDF",
+			"/in/notes":      "/in/2025/05/notes",
+		}},
+		{"dest", func(o *Options) { o.Dest = filepath.FromSlash("/out") }, map[string]string{
+			"/in/Report.PDF": "/out/2025/05/doc/Report.PDF",
+			"/in/notes":      "/out/2025/05/notes",
+		}},
+		{"year-month", func(o *Options) { o.DateLayout = "year-month" }, map[string]string{
+			"/in/Report.PDF": "/in/2025-05/doc/Report.PDF",
+			"/in/notes":      "/in/2025-05/notes",
+		}},
+		{"week", func(o *Options) { o.DateLayout = "week" }, map[string]string{
+			"/in/Report.PDF": "/in/2025/W18/doc/Report.PDF",
+			"/in/notes":      "/in/2025/W18/notes",
+		}},
+		{"quarter", func(o *Options) { o.DateLayout = "quarter" }, map[string]string{
+			"/in/Report.PDF": "/in/2025/Q2/doc/Report.PDF",
+			"/in/notes":      "/in/2025/Q2/notes",
+		}},
+		{"template", func(o *Options) { o.Template = "{{.Kind}}/{{.Ext}}/{{.Year}}" }, map[string]string{
+			"/in/Report.PDF": "/in/doc/pdf/2025/Report.PDF",
+			"/in/notes":      "/in/2025/notes",
+		}},
+		{"rename", func(o *Options) {
+			o.RenameTemplate = "{{.Year}}-{{.Month}}-{{.Day}} {{.Base | slug}}{{if .Ext}}.{{.Ext}}{{end}}"
+		}, map[string]string{
+			"/in/Report.PDF": "/in/2025/05/doc/2025-05-02 report.pdf",
+			"/in/notes":      "/in/2025/05/2025-05-02 notes",
+		}},
+		{"routes", func(o *Options) { o.Routes = map[string]string{"doc": filepath.FromSlash("/docs")} }, map[string]string{
+			"/in/Report.PDF": "/docs/2025/05/doc/Report.PDF",
+			"/in/notes":      "/in/2025/05/notes",
+		}},
+		{"sanitize", func(o *Options) { o.Template = "{{.Year}}: {{.Kind}}"; o.Sanitize = "_" }, map[string]string{
+			"/in/Report.PDF": "/in/2025_ doc/Report.PDF",
+			"/in/notes":      "/in/2025__/notes",
+		}},
+		{"confined", func(o *Options) { o.Template = "../../{{.Year}}" }, map[string]string{
+			"/in/Report.PDF": "/in/2025/Report.PDF",
+			"/in/notes":      "/in/2025/notes",
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newTestFS(t, map[string]string{"/in/Report.PDF": "", "/in/notes/x.txt": ""})
+			meta := added(map[string]time.Time{"/in/Report.PDF": date, "/in/notes": date})
+			opts := fakeOptions(m, meta, date)
+			tc.set(&opts)
+			moves, err := Plan(filepath.FromSlash("/in"), opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkPlan(t, moves, tc.want)
+		})
+	}
+}
+
+func TestPlanTemplateErrors(t *testing.T) {
+	date := time.Date(2025, 5, 2, 15, 4, 5, 0, time.UTC)
+	for _, tc := range []struct {
+		name string
+		set  func(*Options)
+		want string
+	}{
+		{"bad name", func(o *Options) { o.RenameTemplate = "{{.Kind}}/{{.Name}}" }, "bad name"},
+		{"unknown variable", func(o *Options) { o.Template = "{{.Nope}}" }, "Nope"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newTestFS(t, map[string]string{"/in/a.pdf": ""})
+			meta := added(map[string]time.Time{"/in/a.pdf": date})
+			opts := fakeOptions(m, meta, date)
+			tc.set(&opts)
+			_, err := Plan(filepath.FromSlash("/in"), opts)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("got %v, want an error about %q", err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+//go:build cgo
+
+package mvfiles
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+	"unsafe"
+
+	"github.com/progrium/darwinkit/macos/foundation"
+	"github.com/progrium/darwinkit/objc"
+)
+
+// trashFile moves path to the Trash the way the Finder does
+// and returns where it ended up.
+func trashFile(path string) (trashed string, err error) {
+	var ok bool
+	s := strings.Clone(path)
+	objc.WithAutoreleasePool(func() {
+		url := foundation.NewURLFileURLWithPath(s)
+		vals := withOutParams(2, func(out []unsafe.Pointer) {
+			ok = foundation.FileManager_DefaultManager().TrashItemAtURLResultingItemURLError(url, out[0], out[1])
+		})
+		switch {
+		case !ok && vals[1] != nil:
+			err = &fs.PathError{Op: "trash", Path: path, Err: cocoaError(foundation.ErrorFrom(vals[1]))}
+		case !ok:
+			err = fmt.Errorf("could not move %q to the Trash", path)
+		case vals[0] != nil:
+			trashed = strings.Clone(foundation.URLFrom(vals[0]).Path())
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+	return trashed, nil
+}
@@ -0,0 +1,44 @@
+//go:build cgo
+
+package mvfiles
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTrashFile(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip(err)
+	}
+	// Files on the same volume as the home folder go to ~/.Trash
+	dir, err := os.MkdirTemp(home, ".scooter-test-")
+	if err != nil {
+		t.Skip(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	name := filepath.Join(dir, "trash me.txt")
+	if err = os.WriteFile(name, []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	trashed, err := trashFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Remove(trashed) })
+	if _, err = os.Lstat(name); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("%s still exists after trashing: %v", name, err)
+	}
+	if b, err := os.ReadFile(trashed); err != nil || string(b) != "hello" {
+		t.Errorf("reading trashed file %q: %q, %v", trashed, b, err)
+	}
+
+	_, err = trashFile(filepath.Join(dir, "missing.txt"))
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("trashing a missing file: got %v, want fs.ErrNotExist", err)
+	}
+}
@@ -0,0 +1,59 @@
+//go:build !darwin || !cgo
+
+package mvfiles
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// trashFile moves path to the user's Trash and returns where it ended up.
+// On macOS without cgo, that is ~/.Trash. Elsewhere, it follows the
+// freedesktop.org trash specification, which Windows users can ignore.
+func trashFile(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	if runtime.GOOS == "darwin" {
+		trashed, err := resolveConflict(osFiles{}, ConflictRename, filepath.Join(home, ".Trash", filepath.Base(abs)), nil)
+		if err != nil {
+			return "", err
+		}
+		return trashed, moveFile(abs, trashed)
+	}
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		dir = filepath.Join(home, ".local", "share")
+	}
+	dir = filepath.Join(dir, "Trash")
+	if err = os.MkdirAll(filepath.Join(dir, "files"), 0o700); err != nil {
+		return "", err
+	}
+	if err = os.MkdirAll(filepath.Join(dir, "info"), 0o700); err != nil {
+		return "", err
+	}
+	trashed, err := resolveConflict(osFiles{}, ConflictRename, filepath.Join(dir, "files", filepath.Base(abs)), nil)
+	if err != nil {
+		return "", err
+	}
+	info := fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+		(&url.URL{Path: abs}).EscapedPath(), time.Now().Format("2006-01-02T15:04:05"))
+	infoName := filepath.Join(dir, "info", filepath.Base(trashed)+".trashinfo")
+	if err = os.WriteFile(infoName, []byte(info), 0o600); err != nil {
+		return "", err
+	}
+	if err = moveFile(abs, trashed); err != nil {
+		_ = os.Remove(infoName)
+		return "", err
+	}
+	return trashed, nil
+}
@@ -0,0 +1,55 @@
+//go:build cgo
+
+package mvfiles
+
+import (
+	"github.com/progrium/darwinkit/macos/foundation"
+	"github.com/progrium/darwinkit/macos/uti"
+	"github.com/progrium/darwinkit/objc"
+)
+
+// utiKinds maps Uniform Type Identifiers to kinds.
+// A file gets the kind of the first type it conforms to,
+// so more specific types must come before the types they conform to.
+var utiKinds = []struct{ id, kind string }{
+	{"public.html", "web"},
+	{"public.css", "web"},
+	{"com.netscape.javascript-source", "web"},
+	{"com.apple.mail.email", "email"},
+	{"com.apple.mail.emlx", "email"},
+	{"public.json", "data"},
+	{"public.comma-separated-values-text", "data"},
+	{"public.spreadsheet", "data"},
+	{"org.idpf.epub-container", "book"},
+	{"com.adobe.pdf", "doc"},
+	{"public.presentation", "doc"},
+	{"public.movie", "video"},
+	{"public.audio", "audio"},
+	{"public.3d-content", "3d"},
+	{"public.image", "image"},
+	{"com.apple.installer-package-archive", "installer"},
+	{"com.microsoft.windows-installer", "installer"},
+	{"com.apple.disk-image-udif", "installer"},
+	{"public.iso-image", "disk-image"},
+	{"com.apple.disk-image", "disk-image"},
+	{"public.archive", "archive"},
+	{"public.font", "font"},
+	{"public.source-code", "code"},
+	{"public.text", "doc"},
+	{"public.composite-content", "doc"},
+	{"com.apple.application", "app"},
+}
+
+// getUTIKind returns the kind of the file at path based on its content type
+// as reported by the system, or the empty string if no kind matches.
+func getUTIKind(path string) (string, error) {
+	return resourceValue(path, foundation.URLContentTypeKey, func(obj objc.Object) (string, error) {
+		contentType := uti.TypeFrom(obj.Ptr())
+		for _, k := range utiKinds {
+			if contentType.ConformsToType(uti.Type_TypeWithIdentifier(k.id)) {
+				return k.kind, nil
+			}
+		}
+		return "", nil
+	})
+}
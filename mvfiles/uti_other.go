@@ -0,0 +1,9 @@
+//go:build !darwin || !cgo
+
+package mvfiles
+
+import "errors"
+
+func getUTIKind(path string) (string, error) {
+	return "", errors.New("classifying by UTI requires macOS and cgo")
+}
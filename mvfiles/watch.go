@@ -0,0 +1,209 @@
+package mvfiles
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Watch organizes app.dir each time its contents change. Files are only moved
+// once their size and modification time have stayed the same for
+// app.opts.Settle, so that downloads, exports, and copies in progress
+// are left alone. What it has moved, what has failed to move, and what is
+// still settling are remembered in app.stateDir across restarts.
+func (app *appEnv) Watch(ctx context.Context) error {
+	if app.opts.Copy {
+		return errors.New("cannot watch with -copy because files are never moved out of the way")
+	}
+	st, err := loadWatchState(app.stateDir, app.dir)
+	if err != nil {
+		return err
+	}
+	changed := make(chan struct{}, 1)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- watchDir(app.watchedDirs, changed)
+	}()
+	app.Info("watching", "dir", app.dir, "settle", app.opts.Settle, "state", st.path)
+	var wait time.Duration
+	if st.Changed != nil {
+		// Pick up the debounce that was interrupted
+		wait = max(time.Until(st.Changed.Add(app.debounce)), 0)
+		app.Debug("resuming debounce", "changed", *st.Changed, "wait", wait)
+	}
+	timer := time.NewTimer(wait)
+	seen := st.seen(app.dir)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errc:
+			return err
+		case <-changed:
+			timer.Reset(app.debounce)
+			if err := app.recordChange(ctx, time.Now()); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			}
+		case <-timer.C:
+			var err error
+			seen, err = app.organizeStable(ctx, seen)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			}
+			if len(seen) > 0 {
+				// Check back on files that were still changing
+				timer.Reset(app.nextCheck(seen))
+			}
+		}
+	}
+}
+
+// watchedDirs returns app.dir and, with app.opts.Recursive,
+// the directories under it down to app.opts.MaxDepth,
+// so that files added to subdirectories are noticed too.
+// Hidden directories and those that can't be read are left out.
+func (app *appEnv) watchedDirs() []string {
+	dirs := []string{app.dir}
+	if !app.opts.Recursive {
+		return dirs
+	}
+	_ = filepath.WalkDir(app.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || path == app.dir {
+			return nil
+		}
+		rel, _ := filepath.Rel(app.dir, path)
+		depth := strings.Count(filepath.ToSlash(rel), "/") + 1
+		if strings.HasPrefix(d.Name(), ".") ||
+			app.opts.MaxDepth > 0 && depth >= app.opts.MaxDepth {
+			return fs.SkipDir
+		}
+		dirs = append(dirs, path)
+		return nil
+	})
+	return dirs
+}
+
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+func statFile(name string) (fileState, error) {
+	info, err := os.Lstat(name)
+	if err != nil {
+		return fileState{}, err
+	}
+	return fileState{info.Size(), info.ModTime()}, nil
+}
+
+// settling is the state of a file that isn't ready to move
+// and when it was first seen in that state.
+type settling struct {
+	fileState
+	since time.Time
+}
+
+// nextCheck returns how long to wait before the first of the files in seen
+// will have settled, but at least app.debounce.
+func (app *appEnv) nextCheck(seen map[string]settling) time.Duration {
+	wait := app.opts.Settle
+	now := time.Now()
+	for _, s := range seen {
+		wait = min(wait, s.since.Add(app.opts.Settle).Sub(now))
+	}
+	return max(wait, app.debounce)
+}
+
+// recordChange saves that app.dir changed at now
+// and hasn't been organized since.
+func (app *appEnv) recordChange(ctx context.Context, now time.Time) (err error) {
+	unlock, err := lockDir(ctx, app.dir, false)
+	if errors.Is(err, errLocked) {
+		// Another run is going, and a pass will follow it anyway
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, unlock())
+	}()
+	state, err := loadWatchState(app.stateDir, app.dir)
+	if err != nil {
+		return err
+	}
+	state.Changed = &now
+	return state.save(now)
+}
+
+// organizeStable moves the planned files whose state has matched seen
+// for app.opts.Settle and returns the state of the files that are not yet
+// ready to move.
+func (app *appEnv) organizeStable(ctx context.Context, seen map[string]settling) (unstable map[string]settling, err error) {
+	unlock, err := lockDir(ctx, app.dir, false)
+	if errors.Is(err, errLocked) {
+		// Try again after the other run
+		app.Debug("waiting for lock", "dir", app.dir)
+		return seen, nil
+	}
+	if err != nil {
+		return seen, err
+	}
+	defer func() {
+		err = errors.Join(err, unlock())
+	}()
+	// Settling is tracked here, rather than by modification time in Plan,
+	// since copies can keep the modification times of their originals
+	opts := app.opts
+	opts.Settle = 0
+	moves, err := Plan(app.dir, opts)
+	if err != nil {
+		return seen, err
+	}
+	// The state is read again each time, in case it was reset
+	state, err := loadWatchState(app.stateDir, app.dir)
+	if err != nil {
+		return seen, err
+	}
+	now := time.Now()
+	unstable = make(map[string]settling)
+	states := make(map[string]fileState)
+	var ready []Move
+	for _, m := range moves {
+		st, err := statFile(m.Old)
+		if err != nil {
+			continue
+		}
+		if reason := state.skip(m.Old, st); reason != "" {
+			app.Debug("skipping", "path", m.Old, "reason", reason)
+			continue
+		}
+		prev, ok := seen[m.Old]
+		if !ok || prev.fileState != st {
+			prev = settling{st, now}
+		}
+		if ok && now.Sub(prev.since) >= app.opts.Settle {
+			ready = append(ready, m)
+			states[m.Old] = st
+			continue
+		}
+		app.Debug("waiting to settle", "path", m.Old)
+		unstable[m.Old] = prev
+	}
+	state.setSeen(unstable)
+	state.Changed = nil
+	switch {
+	case len(ready) == 0:
+	case app.dryRun:
+		err = app.printPlan(ready)
+	default:
+		err = app.execute(ctx, ready)
+		app.recordWatch(state, ready, states, err)
+	}
+	return unstable, errors.Join(err, state.save(now))
+}
@@ -0,0 +1,95 @@
+package mvfiles
+
+import (
+	"syscall"
+)
+
+const oEvtOnly = 0x8000 // O_EVTONLY from <sys/fcntl.h>
+
+// watchDir uses kqueue to send on changed whenever an entry is added,
+// removed, or renamed in one of the directories returned by dirs.
+// Since kqueue only watches the directories it's given, dirs is called
+// again after each change to watch new subdirectories and drop old ones.
+// It blocks until an error occurs.
+func watchDir(dirs func() []string, changed chan<- struct{}) error {
+	kq, err := syscall.Kqueue()
+	if err != nil {
+		return err
+	}
+	defer syscall.Close(kq)
+	fds := make(map[string]int)
+	defer func() {
+		for _, fd := range fds {
+			syscall.Close(fd)
+		}
+	}()
+
+	register := func() error {
+		keep := make(map[string]bool)
+		for i, dir := range dirs() {
+			keep[dir] = true
+			if _, ok := fds[dir]; ok {
+				continue
+			}
+			fd, err := syscall.Open(dir, oEvtOnly, 0)
+			if err != nil {
+				if i == 0 {
+					return err
+				}
+				// Subdirectories can go away between listing and opening
+				continue
+			}
+			var ev syscall.Kevent_t
+			syscall.SetKevent(&ev, fd, syscall.EVFILT_VNODE, syscall.EV_ADD|syscall.EV_CLEAR)
+			ev.Fflags = syscall.NOTE_WRITE | syscall.NOTE_RENAME | syscall.NOTE_DELETE
+			if _, err = syscall.Kevent(kq, []syscall.Kevent_t{ev}, nil, nil); err != nil {
+				syscall.Close(fd)
+				return err
+			}
+			fds[dir] = fd
+		}
+		for dir, fd := range fds {
+			if !keep[dir] {
+				// Closing the descriptor removes its event
+				syscall.Close(fd)
+				delete(fds, dir)
+			}
+		}
+		return nil
+	}
+	if err := register(); err != nil {
+		return err
+	}
+	events := make([]syscall.Kevent_t, 16)
+	for {
+		n, err := syscall.Kevent(kq, nil, events, nil)
+		if err == syscall.EINTR {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			continue
+		}
+		for _, ev := range events[:n] {
+			if ev.Fflags&(syscall.NOTE_DELETE|syscall.NOTE_RENAME) == 0 {
+				continue
+			}
+			// Whatever is at the path now gets registered again
+			for dir, fd := range fds {
+				if fd == int(ev.Ident) {
+					syscall.Close(fd)
+					delete(fds, dir)
+				}
+			}
+		}
+		if err := register(); err != nil {
+			return err
+		}
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+}
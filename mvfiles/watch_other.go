@@ -0,0 +1,40 @@
+//go:build !darwin
+
+package mvfiles
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// watchDir polls the directories returned by dirs and sends on changed
+// whenever their entries differ. It blocks until an error occurs.
+func watchDir(dirs func() []string, changed chan<- struct{}) error {
+	var prev []string
+	for {
+		var names []string
+		for i, dir := range dirs() {
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				if i == 0 {
+					return err
+				}
+				// Subdirectories can go away between listing and reading
+				continue
+			}
+			for _, entry := range entries {
+				names = append(names, filepath.Join(dir, entry.Name()))
+			}
+		}
+		if !slices.Equal(prev, names) {
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		}
+		prev = names
+		time.Sleep(time.Second)
+	}
+}
@@ -0,0 +1,119 @@
+package mvfiles
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestWatchedDirs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a/b/c", ".hidden/d", "e"} {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.FromSlash(name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a", "f.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		recursive bool
+		maxDepth  int
+		want      []string
+	}{
+		{false, 0, []string{"."}},
+		{true, 0, []string{".", "a", "a/b", "a/b/c", "e"}},
+		{true, 2, []string{".", "a", "e"}},
+	} {
+		app := &appEnv{dir: dir}
+		app.opts.Recursive = tc.recursive
+		app.opts.MaxDepth = tc.maxDepth
+		var got []string
+		for _, path := range app.watchedDirs() {
+			rel, _ := filepath.Rel(dir, path)
+			got = append(got, filepath.ToSlash(rel))
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("recursive %v, max depth %d: got %q, want %q",
+				tc.recursive, tc.maxDepth, got, tc.want)
+		}
+	}
+}
+
+func TestWatchDirRecursive(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "sub")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	app := &appEnv{dir: dir}
+	app.opts.Recursive = true
+	changed := make(chan struct{}, 1)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- watchDir(app.watchedDirs, changed)
+	}()
+	// Let the watcher see the starting state
+	time.Sleep(1500 * time.Millisecond)
+	select {
+	case <-changed:
+	default:
+	}
+	wait := func(what string) {
+		t.Helper()
+		select {
+		case <-changed:
+		case err := <-errc:
+			t.Fatalf("watchDir: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no change seen after %s", what)
+		}
+	}
+	// A subdirectory made after watching began is watched too
+	newDir := filepath.Join(sub, "new")
+	if err := os.Mkdir(newDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	wait("making a subdirectory")
+	if err := os.WriteFile(filepath.Join(newDir, "a.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	wait("adding a file to the new subdirectory")
+}
+
+func TestRecordChange(t *testing.T) {
+	app := &appEnv{dir: t.TempDir(), stateDir: t.TempDir()}
+	changed := time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC)
+	if err := app.recordChange(context.Background(), changed); err != nil {
+		t.Fatal(err)
+	}
+	st, err := loadWatchState(app.stateDir, app.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Changed == nil || !st.Changed.Equal(changed) {
+		t.Errorf("changed = %v, want %v", st.Changed, changed)
+	}
+	// Saving replaces the file without leaving temporary files
+	st.Changed = nil
+	if err = st.save(changed); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(filepath.Dir(st.path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != filepath.Base(st.path) {
+		t.Errorf("state directory has %d entries, want only %s", len(entries), filepath.Base(st.path))
+	}
+	if st, err = loadWatchState(app.stateDir, app.dir); err != nil {
+		t.Fatal(err)
+	}
+	if st.Changed != nil {
+		t.Errorf("changed = %v after organizing, want nil", st.Changed)
+	}
+}
@@ -0,0 +1,309 @@
+package mvfiles
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	// watchMaxFailures is how many times watch tries to move a file
+	// before leaving it alone until its state is reset.
+	watchMaxFailures = 3
+	// watchRetention is how long watch remembers the files it moved.
+	watchRetention = 30 * 24 * time.Hour
+)
+
+// defaultStateDir returns where watch keeps its state: Application Support
+// on macOS and $XDG_STATE_HOME elsewhere.
+func defaultStateDir() string {
+	if runtime.GOOS == "darwin" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return ""
+		}
+		return filepath.Join(dir, AppName)
+	}
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "scooter")
+}
+
+// watchState is what watch remembers about a directory across restarts.
+// Files are keyed by their absolute path.
+type watchState struct {
+	Dir string `json:"dir"`
+	// Processed are the files that were moved, in the state they were in,
+	// so that they aren't moved again if they come back unchanged.
+	Processed map[string]processedFile `json:"processed,omitempty"`
+	// Failed are the files that could not be moved. After watchMaxFailures,
+	// they are left alone until the state is reset.
+	Failed map[string]failedFile `json:"failed,omitempty"`
+	// Settling are the files waiting for -settle to pass.
+	Settling map[string]settlingFile `json:"settling,omitempty"`
+	// Changed is when watch last saw the directory change without
+	// organizing it since, so that a restart waits out the rest of -debounce
+	// instead of moving files in the middle of a burst of changes.
+	Changed *time.Time `json:"changed,omitempty"`
+	// path is the file the state is saved to, or empty not to save it
+	path string
+}
+
+type processedFile struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mtime"`
+	New     string    `json:"destination"`
+	At      time.Time `json:"at"`
+}
+
+type failedFile struct {
+	Error    string    `json:"error"`
+	Failures int       `json:"failures"`
+	At       time.Time `json:"at"`
+}
+
+type settlingFile struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mtime"`
+	Since   time.Time `json:"since"`
+}
+
+// watchStatePath returns the file in stateDir holding the state for dir.
+func watchStatePath(stateDir, dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return filepath.Join(stateDir, "watch", hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// loadWatchState reads the state for dir from stateDir.
+// If stateDir is empty, the state is kept in memory only.
+func loadWatchState(stateDir, dir string) (*watchState, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	st := &watchState{Dir: abs}
+	if stateDir != "" {
+		if st.path, err = watchStatePath(stateDir, dir); err != nil {
+			return nil, err
+		}
+		if err = readWatchState(st.path, st); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	if st.Processed == nil {
+		st.Processed = make(map[string]processedFile)
+	}
+	if st.Failed == nil {
+		st.Failed = make(map[string]failedFile)
+	}
+	return st, nil
+}
+
+func readWatchState(name string, st *watchState) error {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(b, st); err != nil {
+		return fmt.Errorf("reading watch state %q: %w", name, err)
+	}
+	return nil
+}
+
+// save writes st to its file, replacing the old one all at once.
+// Processed files older than watchRetention are forgotten first.
+func (st *watchState) save(now time.Time) error {
+	if st.path == "" {
+		return nil
+	}
+	for path, p := range st.Processed {
+		if now.Sub(p.At) > watchRetention {
+			delete(st.Processed, path)
+		}
+	}
+	b, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(st.path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(st.path), ".watch-*.json")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	if err == nil {
+		// Make sure the data is on disk before the rename can be
+		err = f.Sync()
+	}
+	if err = errors.Join(err, f.Close()); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), st.path)
+}
+
+// seen returns the files waiting to settle in the form Watch uses,
+// with paths under dir.
+func (st *watchState) seen(dir string) map[string]settling {
+	seen := make(map[string]settling, len(st.Settling))
+	for path, s := range st.Settling {
+		if rel, err := filepath.Rel(st.Dir, path); err == nil {
+			path = filepath.Join(dir, rel)
+		}
+		seen[path] = settling{fileState{s.Size, s.ModTime}, s.Since}
+	}
+	return seen
+}
+
+// setSeen records the files waiting to settle.
+func (st *watchState) setSeen(seen map[string]settling) {
+	st.Settling = make(map[string]settlingFile, len(seen))
+	for path, s := range seen {
+		st.Settling[st.key(path)] = settlingFile{s.size, s.modTime, s.since}
+	}
+}
+
+// key returns the key of the file at path.
+func (st *watchState) key(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// skip returns why the file at path in state fs should be left alone
+// or the empty string if it can be moved.
+func (st *watchState) skip(path string, fs fileState) string {
+	path = st.key(path)
+	if p, ok := st.Processed[path]; ok && p.Size == fs.size && p.ModTime.Equal(fs.modTime) {
+		return "already moved"
+	}
+	if f, ok := st.Failed[path]; ok && f.Failures >= watchMaxFailures {
+		return fmt.Sprintf("failed %d times", f.Failures)
+	}
+	return ""
+}
+
+// recordWatch notes the outcome of executing moves, which ended with err.
+// states holds the state of each move's source before it was moved.
+func (app *appEnv) recordWatch(st *watchState, moves []Move, states map[string]fileState, err error) {
+	now := time.Now()
+	failures := make(map[string]error)
+	var (
+		fme *FailedMovesError
+		fm  *FailedMove
+	)
+	switch {
+	case errors.As(err, &fme):
+		for _, f := range fme.Failures {
+			failures[f.Old] = f.Err
+		}
+	case errors.As(err, &fm):
+		failures[fm.Old] = fm.Err
+	}
+	for _, m := range moves {
+		key := st.key(m.Old)
+		if app.moved[m.Old] {
+			s := states[m.Old]
+			st.Processed[key] = processedFile{s.size, s.modTime, m.New, now}
+			delete(st.Failed, key)
+			continue
+		}
+		if ferr, ok := failures[m.Old]; ok {
+			f := st.Failed[key]
+			st.Failed[key] = failedFile{ferr.Error(), f.Failures + 1, now}
+		}
+	}
+}
+
+// WatchState prints what watch remembers about app.dir.
+// With app.reset, it forgets all of it, and with app.retry,
+// it forgets the failures so that those files are tried again.
+func (app *appEnv) WatchState(ctx context.Context) (err error) {
+	if app.stateDir == "" {
+		return errors.New("no -state-dir")
+	}
+	if app.reset || app.retry {
+		// Wait for any pass of watch in progress, which saves the state after
+		unlock, err := lockDir(ctx, app.dir, true)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			err = errors.Join(err, unlock())
+		}()
+	}
+	st, err := loadWatchState(app.stateDir, app.dir)
+	if err != nil {
+		return err
+	}
+	if app.reset {
+		err = os.Remove(st.path)
+		if errors.Is(err, fs.ErrNotExist) {
+			err = nil
+		}
+		if err == nil {
+			app.Info("reset watch state", "dir", st.Dir, "path", st.path)
+		}
+		return err
+	}
+	if app.retry {
+		clear(st.Failed)
+		return st.save(time.Now())
+	}
+	if app.format == formatJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(st)
+	}
+	fmt.Printf("Watch state for %s (%s)\n", st.Dir, st.path)
+	fmt.Printf("%s moved, %s failed, %s settling\n",
+		plural(len(st.Processed), "file"), plural(len(st.Failed), "file"), plural(len(st.Settling), "file"))
+	if st.Changed != nil {
+		fmt.Printf("Changed at %s and not organized since\n", formatDate(*st.Changed, time.DateTime))
+	}
+	fmt.Println()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATE\tPATH\tAT\tDETAIL")
+	for _, path := range slices.Sorted(maps.Keys(st.Failed)) {
+		f := st.Failed[path]
+		detail := fmt.Sprintf("%s: %s", plural(f.Failures, "failure"), strings.ReplaceAll(f.Error, "\n", " "))
+		if f.Failures >= watchMaxFailures {
+			detail = "given up after " + detail
+		}
+		fmt.Fprintf(tw, "failed\t%s\t%s\t%s\n", path, formatDate(f.At, time.DateTime), detail)
+	}
+	for _, path := range slices.Sorted(maps.Keys(st.Settling)) {
+		s := st.Settling[path]
+		fmt.Fprintf(tw, "settling\t%s\t%s\t%s\n", path, formatDate(s.Since, time.DateTime), formatSize(s.Size))
+	}
+	for _, path := range slices.Sorted(maps.Keys(st.Processed)) {
+		p := st.Processed[path]
+		fmt.Fprintf(tw, "moved\t%s\t%s\t%s\n", path, formatDate(p.At, time.DateTime), p.New)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,110 @@
+package mvfiles
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+)
+
+// whereResult is where a file from the journal is now.
+type whereResult struct {
+	Time, Old, Now string
+	Missing        bool
+}
+
+// where searches entries for files whose original names match
+// the case insensitive glob pattern, and follows later moves,
+// trashing, and undoing to find where each one is now.
+func where(entries []journalEntry, pattern string) ([]whereResult, error) {
+	pattern = strings.ToLower(pattern)
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	var results []whereResult
+	// hops are later moves of files already followed
+	hops := make(map[int]bool)
+	for i, e := range entries {
+		if hops[i] {
+			continue
+		}
+		if e.Action != actionMove && e.Action != actionCopy && e.Action != actionTrash {
+			continue
+		}
+		if ok, _ := filepath.Match(pattern, strings.ToLower(filepath.Base(e.Old))); !ok {
+			continue
+		}
+		now := follow(entries, i, hops)
+		_, err := os.Lstat(now)
+		results = append(results, whereResult{
+			Time:    e.Time,
+			Old:     e.Old,
+			Now:     now,
+			Missing: err != nil,
+		})
+	}
+	return results, nil
+}
+
+// follow returns where the file put in place by entries[i] is now
+// after the later entries, marking the later moves it followed in hops.
+func follow(entries []journalEntry, i int, hops map[int]bool) string {
+	now := entries[i].New
+	for j := i + 1; j < len(entries); j++ {
+		later := entries[j]
+		switch {
+		case later.Action == actionUndo && later.New == now:
+			now = later.Old
+		case (later.Action == actionMove || later.Action == actionTrash) && later.Old == now:
+			now = later.New
+			hops[j] = true
+		}
+	}
+	return now
+}
+
+// Where prints where the files matching app.args[0] went.
+// If app.dir is set, it searches the origins recorded on the files in app.dir
+// instead of the journal.
+func (app *appEnv) Where(ctx context.Context) error {
+	var results []whereResult
+	if app.dir != "" {
+		var err error
+		if results, err = whereFromOrigins(app.dir, app.args[0]); err != nil {
+			return err
+		}
+	} else {
+		// Moves are only recorded in the journal, so without one
+		// there is nothing to search rather than nothing that matches
+		if app.opts.Journal == "" {
+			return errors.New("no journal file to search; use -dir to search the origins recorded on files")
+		}
+		entries, err := readJournal(app.opts.Journal)
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("no journal file to search at %s", app.opts.Journal)
+		}
+		if err != nil {
+			return err
+		}
+		if results, err = where(entries, app.args[0]); err != nil {
+			return err
+		}
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("no moves match %q", app.args[0])
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tORIGINAL\tNOW")
+	for _, res := range results {
+		now := res.Now
+		if res.Missing {
+			now += " (missing)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", res.Time, res.Old, now)
+	}
+	return tw.Flush()
+}
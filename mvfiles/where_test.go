@@ -0,0 +1,49 @@
+package mvfiles
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWhereNoJournal(t *testing.T) {
+	dir := t.TempDir()
+	for _, tc := range []struct {
+		name, journal, want string
+	}{
+		{"disabled", "", "no journal file"},
+		{"missing", filepath.Join(dir, "journal.csv"), "no journal file to search at"},
+	} {
+		app := &appEnv{args: []string{"*.pdf"}}
+		app.opts.Journal = tc.journal
+		err := app.Where(context.Background())
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: got %v, want an error containing %q", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestWhereFollowsMoves(t *testing.T) {
+	dir := t.TempDir()
+	final := filepath.Join(dir, "b", "Report.pdf")
+	if err := os.MkdirAll(filepath.Dir(final), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(final, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	entries := []journalEntry{
+		{Action: actionMove, Old: filepath.Join(dir, "Report.pdf"), New: filepath.Join(dir, "a", "Report.pdf")},
+		{Action: actionMove, Old: filepath.Join(dir, "notes.txt"), New: filepath.Join(dir, "a", "notes.txt")},
+		{Action: actionMove, Old: filepath.Join(dir, "a", "Report.pdf"), New: final},
+	}
+	results, err := where(entries, "report.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Now != final || results[0].Missing {
+		t.Errorf("got %+v, want one result at %s", results, final)
+	}
+}
@@ -0,0 +1,89 @@
+package mvfiles
+
+import (
+	"bytes"
+	"io/fs"
+	"syscall"
+	"unsafe"
+)
+
+const xattrNoFollow = 0x0001 // XATTR_NOFOLLOW from <sys/xattr.h>
+
+func listXattrs(path string) ([]string, error) {
+	p, err := syscall.BytePtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+	size, _, errno := syscall.Syscall6(syscall.SYS_LISTXATTR,
+		uintptr(unsafe.Pointer(p)), 0, 0, xattrNoFollow, 0, 0)
+	if errno != 0 {
+		return nil, &fs.PathError{Op: "listxattr", Path: path, Err: errno}
+	}
+	if size == 0 {
+		return nil, nil
+	}
+	buf := make([]byte, size)
+	size, _, errno = syscall.Syscall6(syscall.SYS_LISTXATTR,
+		uintptr(unsafe.Pointer(p)), uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)),
+		xattrNoFollow, 0, 0)
+	if errno != 0 {
+		return nil, &fs.PathError{Op: "listxattr", Path: path, Err: errno}
+	}
+	var names []string
+	for _, name := range bytes.Split(bytes.TrimSuffix(buf[:size], []byte{0}), []byte{0}) {
+		names = append(names, string(name))
+	}
+	return names, nil
+}
+
+func getXattr(path, name string) ([]byte, error) {
+	p, err := syscall.BytePtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+	n, err := syscall.BytePtrFromString(name)
+	if err != nil {
+		return nil, err
+	}
+	size, _, errno := syscall.Syscall6(syscall.SYS_GETXATTR,
+		uintptr(unsafe.Pointer(p)), uintptr(unsafe.Pointer(n)), 0, 0, 0, xattrNoFollow)
+	if errno != 0 {
+		return nil, &fs.PathError{Op: "getxattr", Path: path, Err: errno}
+	}
+	if size == 0 {
+		return []byte{}, nil
+	}
+	buf := make([]byte, size)
+	size, _, errno = syscall.Syscall6(syscall.SYS_GETXATTR,
+		uintptr(unsafe.Pointer(p)), uintptr(unsafe.Pointer(n)),
+		uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)), 0, xattrNoFollow)
+	if errno != 0 {
+		return nil, &fs.PathError{Op: "getxattr", Path: path, Err: errno}
+	}
+	return buf[:size], nil
+}
+
+// xattrPrefix is the namespace for Scooter's own extended attributes.
+const xattrPrefix = ""
+
+func setXattr(path, name string, value []byte) error {
+	p, err := syscall.BytePtrFromString(path)
+	if err != nil {
+		return err
+	}
+	n, err := syscall.BytePtrFromString(name)
+	if err != nil {
+		return err
+	}
+	var v unsafe.Pointer
+	if len(value) > 0 {
+		v = unsafe.Pointer(&value[0])
+	}
+	_, _, errno := syscall.Syscall6(syscall.SYS_SETXATTR,
+		uintptr(unsafe.Pointer(p)), uintptr(unsafe.Pointer(n)),
+		uintptr(v), uintptr(len(value)), 0, xattrNoFollow)
+	if errno != 0 {
+		return &fs.PathError{Op: "setxattr", Path: path, Err: errno}
+	}
+	return nil
+}
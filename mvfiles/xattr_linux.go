@@ -0,0 +1,91 @@
+package mvfiles
+
+import (
+	"bytes"
+	"io/fs"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// listXattrs returns the names of path's extended attributes
+// in the user namespace, since the others are managed by the system.
+func listXattrs(path string) ([]string, error) {
+	p, err := syscall.BytePtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+	size, _, errno := syscall.Syscall(syscall.SYS_LLISTXATTR,
+		uintptr(unsafe.Pointer(p)), 0, 0)
+	if errno != 0 {
+		return nil, &fs.PathError{Op: "llistxattr", Path: path, Err: errno}
+	}
+	if size == 0 {
+		return nil, nil
+	}
+	buf := make([]byte, size)
+	size, _, errno = syscall.Syscall(syscall.SYS_LLISTXATTR,
+		uintptr(unsafe.Pointer(p)), uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)))
+	if errno != 0 {
+		return nil, &fs.PathError{Op: "llistxattr", Path: path, Err: errno}
+	}
+	var names []string
+	for _, name := range bytes.Split(bytes.TrimSuffix(buf[:size], []byte{0}), []byte{0}) {
+		if strings.HasPrefix(string(name), "user.") {
+			names = append(names, string(name))
+		}
+	}
+	return names, nil
+}
+
+func getXattr(path, name string) ([]byte, error) {
+	p, err := syscall.BytePtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+	n, err := syscall.BytePtrFromString(name)
+	if err != nil {
+		return nil, err
+	}
+	size, _, errno := syscall.Syscall6(syscall.SYS_LGETXATTR,
+		uintptr(unsafe.Pointer(p)), uintptr(unsafe.Pointer(n)), 0, 0, 0, 0)
+	if errno != 0 {
+		return nil, &fs.PathError{Op: "lgetxattr", Path: path, Err: errno}
+	}
+	if size == 0 {
+		return []byte{}, nil
+	}
+	buf := make([]byte, size)
+	size, _, errno = syscall.Syscall6(syscall.SYS_LGETXATTR,
+		uintptr(unsafe.Pointer(p)), uintptr(unsafe.Pointer(n)),
+		uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)), 0, 0)
+	if errno != 0 {
+		return nil, &fs.PathError{Op: "lgetxattr", Path: path, Err: errno}
+	}
+	return buf[:size], nil
+}
+
+// xattrPrefix is the namespace for Scooter's own extended attributes.
+const xattrPrefix = "user."
+
+func setXattr(path, name string, value []byte) error {
+	p, err := syscall.BytePtrFromString(path)
+	if err != nil {
+		return err
+	}
+	n, err := syscall.BytePtrFromString(name)
+	if err != nil {
+		return err
+	}
+	var v unsafe.Pointer
+	if len(value) > 0 {
+		v = unsafe.Pointer(&value[0])
+	}
+	_, _, errno := syscall.Syscall6(syscall.SYS_LSETXATTR,
+		uintptr(unsafe.Pointer(p)), uintptr(unsafe.Pointer(n)),
+		uintptr(v), uintptr(len(value)), 0, 0)
+	if errno != 0 {
+		return &fs.PathError{Op: "lsetxattr", Path: path, Err: errno}
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package mvfiles
+
+import "errors"
+
+// Windows has no extended attributes in the Unix sense.
+
+func listXattrs(path string) ([]string, error) {
+	return nil, nil
+}
+
+func getXattr(path, name string) ([]byte, error) {
+	return nil, errors.ErrUnsupported
+}
+
+const xattrPrefix = ""
+
+func setXattr(path, name string, value []byte) error {
+	return errors.ErrUnsupported
+}